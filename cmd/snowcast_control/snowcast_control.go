@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/IMaloney/snowcast/pkg/client"
 	"github.com/IMaloney/snowcast/pkg/utils"
@@ -17,11 +18,25 @@ import (
 func printHelp(extraCredit bool) {
 	fmt.Println("Commands:")
 	fmt.Println("[station number] --> Plays that station (0 indexed)")
+	if extraCredit {
+		fmt.Println("[station name] --> Plays the station with that name")
+	}
 	fmt.Println("quit q --> Quits Client")
 	fmt.Println("help h --> Prints this message")
 	if extraCredit {
 		fmt.Println("getSongs [station number] --> Prints all the songs playing on that station")
 		fmt.Println("allStations --> Prints all the songs playing on all stations")
+		fmt.Println("request/r [station number] [song] --> Asks the station to play one of its songs next")
+		fmt.Println("voteSkip/v --> Votes to skip the song playing on the station you're listening to")
+		fmt.Println("chat/c [message...] --> Sends a message to everyone listening to your station")
+		fmt.Println("stop --> Stops playing the station you're listening to without disconnecting")
+		fmt.Println("watch/w [station numbers...] --> Prints the song changes of those stations without playing them, or the stations you watch")
+		fmt.Println("unwatch/u [station numbers...] --> Stops printing the song changes of those stations")
+		fmt.Println("search/f [query...] --> Prints the songs on any station whose name or category contains the query")
+		fmt.Println("stations --> Prints the number, name, current song and listeners of every station")
+		fmt.Println("playlist [station number] [num songs] --> Prints the next num songs that will play on that station")
+		fmt.Println("shows [station number] --> Prints the show on that station now and the next show")
+		fmt.Println("admin [password] [command...] --> Runs a server command such as skip [station number]")
 	}
 }
 
@@ -54,10 +69,180 @@ func parseCommand(command string, extraCredit bool, client *client.Client) {
 			} else {
 				fmt.Printf("Command not recognized. Try again.\n")
 			}
+		case "playlist", "p":
+			if extraCredit {
+				if len(vals) != 3 {
+					fmt.Println("Provide a station and a number of songs in order to get the playlist.")
+					return
+				}
+				num, err := strconv.Atoi(vals[1])
+				if err != nil {
+					fmt.Printf("Could not get the playlist of station %s. Did not recognize the number. Try Again.\n", vals[1])
+					return
+				}
+				numSongs, err := strconv.Atoi(vals[2])
+				if err != nil || numSongs <= 0 {
+					fmt.Printf("Could not get %s songs. Did not recognize the number. Try Again.\n", vals[2])
+					return
+				}
+				err = client.GetPlaylist(uint16(num), uint16(numSongs))
+				if err != nil {
+					fmt.Printf("%v\n", err)
+				}
+			} else {
+				fmt.Printf("Command not recognized. Try again.\n")
+			}
+		case "shows", "s":
+			if extraCredit {
+				if len(vals) != 2 {
+					fmt.Println("Provide a station in order to get its shows.")
+					return
+				}
+				num, err := strconv.Atoi(vals[1])
+				if err != nil {
+					fmt.Printf("Could not get the shows of station %s. Did not recognize the number. Try Again.\n", vals[1])
+					return
+				}
+				err = client.GetShows(uint16(num))
+				if err != nil {
+					fmt.Printf("%v\n", err)
+				}
+			} else {
+				fmt.Printf("Command not recognized. Try again.\n")
+			}
+		case "allStations":
+			if extraCredit {
+				err := client.GetAllStationSongs()
+				if err != nil {
+					fmt.Printf("%v\n", err)
+				}
+			} else {
+				fmt.Printf("Command not recognized. Try again.\n")
+			}
+		case "request", "r":
+			if extraCredit {
+				if len(vals) < 3 {
+					fmt.Println("Provide a station and one of its songs to request.")
+					return
+				}
+				num, err := strconv.Atoi(vals[1])
+				if err != nil {
+					fmt.Printf("Could not request a song on station %s. Did not recognize the number. Try Again.\n", vals[1])
+					return
+				}
+				err = client.RequestSong(uint16(num), strings.Join(vals[2:], " "))
+				if err != nil {
+					fmt.Printf("%v\n", err)
+				}
+			} else {
+				fmt.Printf("Command not recognized. Try again.\n")
+			}
+		case "voteSkip", "v":
+			if extraCredit {
+				err := client.VoteSkip()
+				if err != nil {
+					fmt.Printf("%v\n", err)
+				}
+			} else {
+				fmt.Printf("Command not recognized. Try again.\n")
+			}
+		case "chat", "c":
+			if extraCredit {
+				if len(vals) < 2 {
+					fmt.Println("Provide a message to send.")
+					return
+				}
+				// keep the spacing the message was typed with
+				text := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(command), cmd))
+				err := client.Chat(text)
+				if err != nil {
+					fmt.Printf("%v\n", err)
+				}
+			} else {
+				fmt.Printf("Command not recognized. Try again.\n")
+			}
+		case "stop":
+			if extraCredit {
+				err := client.StopListening()
+				if err != nil {
+					fmt.Printf("%v\n", err)
+				}
+			} else {
+				fmt.Printf("Command not recognized. Try again.\n")
+			}
+		case "watch", "w", "unwatch", "u":
+			if extraCredit {
+				stationNums := make([]uint16, 0)
+				for _, val := range vals[1:] {
+					num, err := strconv.Atoi(val)
+					if err != nil {
+						fmt.Printf("Could not watch station %s. Did not recognize the number. Try Again.\n", val)
+						return
+					}
+					stationNums = append(stationNums, uint16(num))
+				}
+				var err error
+				if cmd == "watch" || cmd == "w" {
+					err = client.Watch(stationNums)
+				} else {
+					if len(stationNums) == 0 {
+						fmt.Println("Provide the stations to stop watching.")
+						return
+					}
+					err = client.Unwatch(stationNums)
+				}
+				if err != nil {
+					fmt.Printf("%v\n", err)
+				}
+			} else {
+				fmt.Printf("Command not recognized. Try again.\n")
+			}
+		case "search", "f":
+			if extraCredit {
+				if len(vals) < 2 {
+					fmt.Println("Provide something to search for.")
+					return
+				}
+				err := client.SearchSongs(strings.Join(vals[1:], " "))
+				if err != nil {
+					fmt.Printf("%v\n", err)
+				}
+			} else {
+				fmt.Printf("Command not recognized. Try again.\n")
+			}
+		case "stations", "l":
+			if extraCredit {
+				err := client.ListStations()
+				if err != nil {
+					fmt.Printf("%v\n", err)
+				}
+			} else {
+				fmt.Printf("Command not recognized. Try again.\n")
+			}
+		case "admin", "a":
+			if extraCredit {
+				if len(vals) < 3 {
+					fmt.Println("Provide the admin password and a command to run.")
+					return
+				}
+				err := client.RunAdminCommand(vals[1], strings.Join(vals[2:], " "))
+				if err != nil {
+					fmt.Printf("%v\n", err)
+				}
+			} else {
+				fmt.Printf("Command not recognized. Try again.\n")
+			}
 		case "help", "h":
 			printHelp(extraCredit)
 		default:
 			num, err := strconv.Atoi(cmd)
+			if err != nil && extraCredit {
+				err = client.SetStationByName(strings.Join(vals, " "))
+				if err != nil {
+					fmt.Printf("%v\n", err)
+				}
+				return
+			}
 			if err != nil {
 				fmt.Printf("Could not change to station %s. Did not recognize the number. Try Again.\n", cmd)
 				return
@@ -84,6 +269,9 @@ func repl(c *client.Client, extraCredit bool) {
 		case <-sigChan:
 			c.Quit()
 			return
+		case <-c.Done():
+			// the server went away
+			return
 		case command := <-inputChan:
 			parseCommand(command, extraCredit, c)
 		case reply := <-replyChan:
@@ -100,6 +288,9 @@ func repl(c *client.Client, extraCredit bool) {
 
 func main() {
 	extraCreditMode := flag.Bool("e", false, "runs the client with extra credit")
+	nickname := flag.String("name", "", "nickname you chat with (extra credit)")
+	reconnect := flag.Bool("reconnect", false, "reconnects and tunes back in when the server says it will be back (extra credit)")
+	heartbeat := flag.Int("heartbeat", utils.HEARTBEATTIME, "milliseconds between pings to the server, shortened if the server expects more. 0 sends none (extra credit)")
 	flag.Parse()
 	args := flag.Args()
 	if len(args) < 3 {
@@ -118,10 +309,17 @@ func main() {
 	if err != nil {
 		log.Fatalf("Could not create client. Error:%v", err)
 	}
+	c.SetNickname(*nickname)
+	c.SetHeartbeat(time.Duration(*heartbeat) * time.Millisecond)
+	c.SetReconnect(*reconnect)
 	err = c.Handshake()
 	if err != nil {
 		os.Exit(0)
 	}
+	if *extraCreditMode {
+		// station numbers can have gaps so show which ones exist
+		c.ListStations()
+	}
 	repl(c, *extraCreditMode)
 	fmt.Printf("Thanks for listening!\n")
 }
@@ -4,12 +4,14 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"os/signal"
 	"strconv"
 	"strings"
 	"syscall"
 
+	"github.com/IMaloney/snowcast/pkg/config"
 	"github.com/IMaloney/snowcast/pkg/server"
 	"github.com/IMaloney/snowcast/pkg/utils"
 )
@@ -20,46 +22,142 @@ func printHelpMenu(ec bool) {
 	fmt.Println("print/p --> prints a list of the stations and all the clients listening to each station")
 	fmt.Println("help/h --> prints the help menu")
 	if ec {
+		fmt.Println("quit/q [reconnectSeconds] [reason...] --> tells the clients why the server is going away and when it is back, then quits")
 		fmt.Println("addStation/a [songs...]--> adds a new station to server with [songs...] as music")
+		fmt.Println("    a live:[stdin|fifo:path|udp:port]=[title] entry makes the station play a live feed")
 		fmt.Println("removeStation/r [stationNumber] --> removes station [stationNumber] from radio")
+		fmt.Println("mode/m [stationNumber] [sequential|shuffle|random|once|clock] [noRepeat] --> changes how the station picks its next song")
+		fmt.Println("addSong/as [stationNumber] [song] --> adds [song] to the end of station [stationNumber]")
+		fmt.Println("removeSong/rs [stationNumber] [song] --> removes [song] from station [stationNumber]")
+		fmt.Println("moveSong/ms [stationNumber] [from] [to] --> moves the song at position [from] to position [to]")
+		fmt.Println("skip/s [stationNumber] --> skips the song playing on station [stationNumber]")
+		fmt.Println("pause [stationNumber] --> stops station [stationNumber] sending audio without dropping its listeners")
+		fmt.Println("resume [stationNumber] --> starts a paused station again from where it paused")
+		fmt.Println("migrate/mv [fromStationNumber] [toStationNumber] --> moves every listener of one station onto another")
+		fmt.Println("mute [nickname] --> stops the address of the client with [nickname], and every client on it, chatting, or lists the muted addresses")
+		fmt.Println("unmute [nickname or address] --> lets a muted address chat again")
+		fmt.Println("health [stationNumber] --> prints whether the stations can play their songs and which songs they skip")
+		fmt.Println("drain/d [stationNumber] [fallbackStationNumber] --> removes a station once its song ends, moving its listeners to the fallback")
 	}
 }
 
-func main() {
-	extraCreditMode := flag.Bool("e", false, "runs the server with extra credit")
-	flag.Parse()
+// parseQuit reads the seconds until the server is back and the reason it is going away from the quit command.
+// Both are optional and only extra credit clients are told them
+func parseQuit(args []string, extraCredit bool) (uint16, string) {
+	reason := "The server was stopped"
+	if !extraCredit || len(args) == 0 {
+		return 0, reason
+	}
+	seconds, err := strconv.Atoi(args[0])
+	if err != nil || seconds < 0 || seconds > math.MaxUint16 {
+		// no seconds given so everything is the reason
+		return 0, strings.Join(args, " ")
+	}
+	if len(args) > 1 {
+		reason = strings.Join(args[1:], " ")
+	}
+	return uint16(seconds), reason
+}
+
+// loadConfig reads the config file if one was given, otherwise the config is built from the command line
+func loadConfig(configPath string, extraCredit bool, sourcePort, sourcePassword, stateFile, adminPassword string) (*config.Config, error) {
+	if configPath != "" {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return nil, err
+		}
+		if stateFile != "" {
+			cfg.StateFile = stateFile
+		}
+		if adminPassword != "" {
+			cfg.AdminPassword = adminPassword
+		}
+		return cfg, nil
+	}
 	args := flag.Args()
 	if len(args) < 2 {
-		fmt.Println("run program as follows: ./snowcast_server [port] [mp3 files...]")
-		return
+		return nil, fmt.Errorf("run program as follows: ./snowcast_server [port] [mp3 files...] or ./snowcast_server -c [config file]")
 	}
 	port, err := strconv.Atoi(args[0])
 	if err != nil {
-		log.Fatal("malformed port")
+		return nil, fmt.Errorf("malformed port")
 	}
 	if port <= 0 {
-		log.Fatal("Port should be greater than 0")
+		return nil, fmt.Errorf("Port should be greater than 0")
+	}
+	cfg, err := config.FromArgs(args[0], args[1:], extraCredit)
+	if err != nil {
+		return nil, err
+	}
+	if sourcePort != "" {
+		if sourcePassword == "" {
+			return nil, fmt.Errorf("source clients need a password. Set one with -source-password")
+		}
+		cfg.SourceListen = ":" + sourcePort
+		cfg.SourcePassword = sourcePassword
+	}
+	cfg.StateFile = stateFile
+	cfg.AdminPassword = adminPassword
+	return cfg, nil
+}
+
+// reloadConfig re-reads the config file and applies it to the running server
+func reloadConfig(s *server.Server, configPath string) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Printf("Could not reload config. %v\n", err)
+		return
+	}
+	results := s.Reload(cfg)
+	fmt.Printf("Reloaded config %s with %d changes\n", configPath, len(results))
+	for _, result := range results {
+		fmt.Println(result)
+	}
+}
+
+func main() {
+	configPath := flag.String("c", "", "json config file describing the server and its stations")
+	extraCreditMode := flag.Bool("e", false, "runs the server with extra credit")
+	sourcePort := flag.String("source-port", "", "port that source clients push live audio to (extra credit)")
+	sourcePassword := flag.String("source-password", "", "password source clients log in with")
+	stateFile := flag.String("state", "", "file station positions are saved to and restored from")
+	adminPassword := flag.String("admin-password", "", "password clients send with admin commands (extra credit)")
+	flag.Parse()
+	cfg, err := loadConfig(*configPath, *extraCreditMode, *sourcePort, *sourcePassword, *stateFile, *adminPassword)
+	if err != nil {
+		log.Fatal(err)
 	}
-	files := args[1:]
+	extraCredit := cfg.Features.ExtraCredit
 	msgChan := make(chan string)
 	sigChan := make(chan os.Signal, 1)
 	inputChan := make(chan string)
-	s, err := server.CreateServer(args[0], files, msgChan, *extraCreditMode)
+	s, err := server.CreateServer(cfg, msgChan)
 	if err != nil {
 		log.Fatalf("could not create server. Error: %v", err)
 	}
 	go s.Listen()
-	go utils.ReadInput(inputChan)
+	if cfg.UsesStdin() {
+		// stdin carries the live feed so the command line is unavailable
+		fmt.Println("A station is reading its live feed from stdin. Server commands are disabled.")
+	} else {
+		go utils.ReadInput(inputChan)
+	}
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	hupChan := make(chan os.Signal, 1)
+	if *configPath != "" {
+		signal.Notify(hupChan, syscall.SIGHUP)
+	}
 	for {
 		fmt.Printf("> ")
 		select {
 		case msg := <-msgChan:
-			fmt.Printf(msg)
-			fmt.Println()
+			// messages carry text from clients so they can't be a format string
+			fmt.Println(msg)
 		case <-sigChan:
-			s.Quit()
+			s.Shutdown("The server was stopped", 0)
 			return
+		case <-hupChan:
+			reloadConfig(s, *configPath)
 		case songs := <-inputChan:
 			vals := strings.Fields(songs)
 			if len(vals) == 0 {
@@ -70,12 +168,13 @@ func main() {
 			case "print", "p":
 				s.PrintStationsAndClients()
 			case "quit", "q":
-				s.Quit()
+				reconnectAfter, reason := parseQuit(vals[1:], extraCredit)
+				s.Shutdown(reason, reconnectAfter)
 				return
 			case "help", "h":
-				printHelpMenu(*extraCreditMode)
+				printHelpMenu(extraCredit)
 			case "addStation", "a":
-				if *extraCreditMode {
+				if extraCredit {
 					if len(vals) < 2 {
 						fmt.Printf("need to list songs to make a station.\n")
 						continue
@@ -85,7 +184,7 @@ func main() {
 					fmt.Printf("Could not recognize command. Try again.\n")
 				}
 			case "removeStation", "r":
-				if *extraCreditMode {
+				if extraCredit {
 					if len(vals) < 2 {
 						fmt.Printf("Need to list a station to remove.\n")
 						continue
@@ -103,8 +202,43 @@ func main() {
 				} else {
 					fmt.Printf("Could not recognize command. Try again.\n")
 				}
+			case "mode", "m":
+				if extraCredit {
+					if len(vals) < 3 {
+						fmt.Printf("Need to list a station and a play mode.\n")
+						continue
+					}
+					num, err := strconv.Atoi(vals[1])
+					if err != nil {
+						fmt.Printf("Could not recognize number %s\n", vals[1])
+						continue
+					}
+					noRepeat := 0
+					if len(vals) > 3 {
+						noRepeat, err = strconv.Atoi(vals[3])
+						if err != nil || noRepeat < 0 {
+							fmt.Printf("Could not recognize number %s\n", vals[3])
+							continue
+						}
+					}
+					err = s.SetPlayMode(uint16(num), vals[2], noRepeat)
+					if err != nil {
+						fmt.Printf("Could not change play mode. %v\n", err)
+					}
+				} else {
+					fmt.Printf("Could not recognize command. Try again.\n")
+				}
 			default:
-				fmt.Printf("Could not recognize command. Try again.\n")
+				if !extraCredit {
+					fmt.Printf("Could not recognize command. Try again.\n")
+					continue
+				}
+				result, err := s.RunCommand(vals)
+				if err != nil {
+					fmt.Printf("Could not run command. %v\n", err)
+					continue
+				}
+				fmt.Println(result)
 			}
 		}
 	}
@@ -3,12 +3,17 @@ package client
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"strconv"
 	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
 
 	"github.com/IMaloney/snowcast/pkg/utils"
+	"go.uber.org/atomic"
 )
 
 type Client struct {
@@ -16,9 +21,22 @@ type Client struct {
 	udpPort     int
 	numStations uint16
 	serverAddr  string
-	conn        *net.TCPConn
+	conn        *serverConn
 	exitChan    chan struct{}
+	// closed once the client stops receiving replies
+	doneChan    chan struct{}
 	extraCredit bool
+	// name the client chats with. Empty lets the server pick one
+	nickname string
+	// name of the station to tune in to once the station list arrives
+	pendingStation string
+	pendingMutex   sync.Mutex
+	// time between the pings sent to the server. 0 sends none. The server can ask for a shorter time
+	heartbeat *atomic.Duration
+	// station to tune back in to after reconnecting. -1 when the client isn't listening
+	station *atomic.Int32
+	// whether to reconnect when the server says it will be back
+	reconnect bool
 }
 
 // CreateClient creates the client
@@ -35,12 +53,42 @@ func CreateClient(serverAddr string, serverPort, udpPort int, extraCredit bool)
 		serverPort:  serverPort,
 		udpPort:     udpPort,
 		serverAddr:  serverAddr,
-		conn:        conn,
+		conn:        createServerConn(conn),
 		exitChan:    make(chan struct{}, 1),
+		doneChan:    make(chan struct{}),
 		extraCredit: extraCredit,
+		station:     atomic.NewInt32(-1),
+		heartbeat:   atomic.NewDuration(0),
 	}, nil
 }
 
+// SetNickname sets the name the client chats with. It is sent with hello so it has to be set before the handshake
+func (c *Client) SetNickname(nickname string) {
+	c.nickname = nickname
+}
+
+// SetHeartbeat sets how often the client pings the server once the handshake is done. 0 sends no pings
+func (c *Client) SetHeartbeat(heartbeat time.Duration) {
+	c.heartbeat.Store(heartbeat)
+}
+
+// keepUpWith adopts the heartbeat interval the server expects if it is shorter than the client's
+func (c *Client) keepUpWith(interval time.Duration) {
+	if interval > 0 && interval < c.heartbeat.Load() {
+		c.heartbeat.Store(interval)
+	}
+}
+
+// SetReconnect sets whether the client reconnects when the server says it will be back
+func (c *Client) SetReconnect(reconnect bool) {
+	c.reconnect = reconnect
+}
+
+// Done returns a channel that is closed once the client stops receiving replies from the server
+func (c *Client) Done() <-chan struct{} {
+	return c.doneChan
+}
+
 // SetStation sets the station of the client
 func (c *Client) SetStation(stationNum uint16) error {
 	message, err := utils.CreateSetStationMessage(stationNum)
@@ -51,9 +99,61 @@ func (c *Client) SetStation(stationNum uint16) error {
 	if err != nil {
 		return err
 	}
+	c.station.Store(int32(stationNum))
 	return nil
 }
 
+// SetStationByName tunes in to the station with the given name. The station list is requested to find its number
+func (c *Client) SetStationByName(name string) error {
+	c.pendingMutex.Lock()
+	c.pendingStation = name
+	c.pendingMutex.Unlock()
+	return c.ListStations()
+}
+
+// ListStations requests the number, name, description, current song and listener count of every station
+func (c *Client) ListStations() error {
+	message, err := utils.CreateListStationsMessage()
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// handleStationList describes the stations, or tunes in to the station asked for by name
+func (c *Client) handleStationList(stations []utils.StationEntry) string {
+	c.pendingMutex.Lock()
+	name := c.pendingStation
+	c.pendingStation = ""
+	c.pendingMutex.Unlock()
+	if name != "" {
+		for _, station := range stations {
+			if strings.EqualFold(station.Name, name) {
+				err := c.SetStation(station.Station)
+				if err != nil {
+					return fmt.Sprintf("Could not tune in to %s. Error: %v", name, err)
+				}
+				return fmt.Sprintf("Tuning in to station %d (%s)", station.Station, station.Name)
+			}
+		}
+		return fmt.Sprintf("There is no station named %s", name)
+	}
+	lines := []string{"Stations:"}
+	for _, station := range stations {
+		line := fmt.Sprintf("%d %s", station.Station, station.Name)
+		if station.Description != "" {
+			line += fmt.Sprintf(" (%s)", station.Description)
+		}
+		line += fmt.Sprintf(": playing %s to %d listeners", station.Song, station.Listeners)
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
 // GetStationSongs requests the songs on the listed station
 func (c *Client) GetStationSongs(stationNum uint16) error {
 	message, err := utils.CreateGetStationSongsMessage(stationNum)
@@ -67,23 +167,265 @@ func (c *Client) GetStationSongs(stationNum uint16) error {
 	return nil
 }
 
+// GetAllStationSongs requests the songs and the song playing of every station
+func (c *Client) GetAllStationSongs() error {
+	message, err := utils.CreateGetAllStationSongsMessage()
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SearchSongs requests the songs on every station whose name or tags contain the query
+func (c *Client) SearchSongs(query string) error {
+	message, err := utils.CreateSearchSongsMessage(query)
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// readRest reads the rest of a reply that didn't fit in one read. The header holds the length of the reply
+func (c *Client) readRest(buffer []byte, headerSize int, length func([]byte) int) ([]byte, error) {
+	if len(buffer) < headerSize {
+		header := make([]byte, headerSize-len(buffer))
+		_, err := io.ReadFull(c.conn, header)
+		if err != nil {
+			return nil, err
+		}
+		buffer = append(buffer, header...)
+	}
+	if replyLength := length(buffer); len(buffer) < replyLength {
+		rest := make([]byte, replyLength-len(buffer))
+		_, err := io.ReadFull(c.conn, rest)
+		if err != nil {
+			return nil, err
+		}
+		buffer = append(buffer, rest...)
+	}
+	return buffer, nil
+}
+
+// readSearchResults reads a search results reply and describes every match
+func (c *Client) readSearchResults(buffer []byte) (string, error) {
+	buffer, err := c.readRest(buffer, 7, utils.SearchResultsLength)
+	if err != nil {
+		return "", err
+	}
+	matches, err := utils.ParseSearchResultsMessage(buffer)
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "No songs found", nil
+	}
+	lines := []string{fmt.Sprintf("Found %d songs:", len(matches))}
+	for _, match := range matches {
+		line := fmt.Sprintf("station %d position %d: %s", match.Station, match.Position, match.Song)
+		if len(match.Tags) > 0 {
+			line += fmt.Sprintf(" [%s]", strings.Join(match.Tags, ", "))
+		}
+		if match.OnAir {
+			line += " (on air)"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// readAllStationSongs reads an all station songs reply and renders it as a table
+func (c *Client) readAllStationSongs(buffer []byte) (string, error) {
+	buffer, err := c.readRest(buffer, 7, utils.AllStationSongsLength)
+	if err != nil {
+		return "", err
+	}
+	stations, err := utils.ParseAllStationSongsMessage(buffer)
+	if err != nil {
+		return "", err
+	}
+	table := new(strings.Builder)
+	writer := tabwriter.NewWriter(table, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "STATION\tNAME\tNOW PLAYING\tSONGS")
+	for _, station := range stations {
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\n", station.Station, station.Name, station.NowPlaying, strings.Join(station.Songs, ", "))
+	}
+	writer.Flush()
+	return strings.TrimSuffix(table.String(), "\n"), nil
+}
+
+// readWatching reads the rest of a watching reply and lists the stations the client watches
+func (c *Client) readWatching(buffer []byte) (string, error) {
+	buffer, err := c.readRest(buffer, 7, utils.WatchingLength)
+	if err != nil {
+		return "", err
+	}
+	stations, err := utils.ParseWatchingMessage(buffer)
+	if err != nil {
+		return "", err
+	}
+	if len(stations) == 0 {
+		return "Not watching any stations", nil
+	}
+	lines := []string{fmt.Sprintf("Watching %d stations:", len(stations))}
+	for _, station := range stations {
+		lines = append(lines, fmt.Sprintf("[station %d] now playing: %s", station.Station, station.Song))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// GetPlaylist requests the next numSongs songs that will play on the listed station
+func (c *Client) GetPlaylist(stationNum, numSongs uint16) error {
+	message, err := utils.CreateGetPlaylistMessage(stationNum, numSongs)
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetShows requests the current and next show on the listed station
+func (c *Client) GetShows(stationNum uint16) error {
+	message, err := utils.CreateGetShowsMessage(stationNum)
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// RequestSong asks a station to play one of its songs next
+func (c *Client) RequestSong(stationNum uint16, songName string) error {
+	message, err := utils.CreateRequestSongMessage(stationNum, songName)
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// VoteSkip votes to skip the song playing on the station the client listens to
+func (c *Client) VoteSkip() error {
+	message, err := utils.CreateVoteSkipMessage()
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Chat sends a line of chat to the listeners of the client's station
+func (c *Client) Chat(text string) error {
+	if len(text) > utils.CHATLENGTH {
+		return fmt.Errorf("Chat messages can be at most %d characters", utils.CHATLENGTH)
+	}
+	message, err := utils.CreateChatMessage(text)
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// StopListening stops the client hearing its station without disconnecting
+func (c *Client) StopListening() error {
+	message, err := utils.CreateStopListeningMessage()
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(message)
+	if err != nil {
+		return err
+	}
+	c.station.Store(-1)
+	return nil
+}
+
+// Watch follows the song changes of stations without listening to them. Watching no stations asks which
+// stations the client watches
+func (c *Client) Watch(stationNums []uint16) error {
+	message, err := utils.CreateWatchMessage(stationNums)
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Unwatch stops following the song changes of stations
+func (c *Client) Unwatch(stationNums []uint16) error {
+	message, err := utils.CreateUnwatchMessage(stationNums)
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// RunAdminCommand asks the server to run a command with the admin password
+func (c *Client) RunAdminCommand(password, command string) error {
+	message, err := utils.CreateAdminCommandMessage(password, command)
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 // sendHello sends hello to the server
-func (c *Client) sendHello() {
+func (c *Client) sendHello() error {
 	message, err := utils.CreateHelloMessage(uint16(c.udpPort))
+	if c.extraCredit && c.nickname != "" {
+		message, err = utils.CreateNamedHelloMessage(uint16(c.udpPort), c.nickname)
+	}
 	if err != nil {
-		c.conn.Close()
-		log.Fatalf("could not create hello message for server. Err: %v", err)
+		return fmt.Errorf("could not create hello message for server. Err: %v", err)
 	}
 	_, err = c.conn.Write(message)
 	if err != nil {
-		c.conn.Close()
-		log.Fatalf("could not send message to server. Err: %v", err)
+		return fmt.Errorf("could not send message to server. Err: %v", err)
 	}
+	return nil
 }
 
 // Handshake starts the handshake between the server and the client
 func (c *Client) Handshake() error {
-	c.sendHello()
+	err := c.sendHello()
+	if err != nil {
+		c.conn.Close()
+		log.Fatal(err)
+	}
 	numStations, err := c.receiveWelcome()
 	if err != nil {
 		c.conn.Close()
@@ -91,9 +433,112 @@ func (c *Client) Handshake() error {
 		return err
 	}
 	fmt.Printf("> The server has %d stations.\n", numStations)
+	if c.extraCredit && c.heartbeat.Load() > 0 {
+		err = c.startHeartbeats()
+		if err != nil {
+			c.conn.Close()
+			close(c.exitChan)
+			return err
+		}
+	}
+	return nil
+}
+
+// startHeartbeats pings the server once right after the handshake to learn the heartbeat interval it expects, then
+// keeps pinging in the background
+func (c *Client) startHeartbeats() error {
+	message, err := utils.CreatePingMessage()
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(message)
+	if err != nil {
+		return fmt.Errorf("could not send ping to server. Err: %v", err)
+	}
+	c.conn.SetReadDeadline(time.Now().Add(c.heartbeat.Load() * utils.HEARTBEATMISSES))
+	buffer := make([]byte, 5)
+	_, err = io.ReadFull(c.conn, buffer)
+	c.conn.SetReadDeadline(time.Time{})
+	if err != nil {
+		return fmt.Errorf("could not receive pong from server. Err: %v", err)
+	}
+	if utils.ReplyType(buffer[0]) != utils.Pong {
+		return fmt.Errorf("Did not receive pong response")
+	}
+	c.keepUpWith(time.Duration(binary.BigEndian.Uint32(buffer[1:5])) * time.Millisecond)
+	go c.sendHeartbeats()
 	return nil
 }
 
+// sendHeartbeats pings the server every heartbeat until the connection closes. A reconnected client pings over its
+// new connection from a new call
+func (c *Client) sendHeartbeats() {
+	conn := c.conn.get()
+	for {
+		time.Sleep(c.heartbeat.Load())
+		message, err := utils.CreatePingMessage()
+		if err != nil {
+			return
+		}
+		_, err = conn.Write(message)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// reconnectAfter waits until the server should be back, then connects to it again, says hello and tunes back in to
+// the station the client was listening to. Returns false if the client quit or the server never came back
+func (c *Client) reconnectAfter(wait time.Duration, replyChan chan string) bool {
+	addr, err := net.ResolveTCPAddr("tcp", c.serverAddr+":"+strconv.Itoa(c.serverPort))
+	if err != nil {
+		replyChan <- fmt.Sprintf("Could not reconnect to the server. Error: %v", err)
+		return false
+	}
+	for try := 1; try <= utils.RECONNECTTRIES; try++ {
+		select {
+		case <-c.exitChan:
+			return false
+		case <-time.After(wait):
+		}
+		wait = utils.RECONNECTTIME * time.Millisecond
+		conn, err := net.DialTCP("tcp", nil, addr)
+		if err != nil {
+			replyChan <- fmt.Sprintf("Could not reconnect to the server (try %d of %d). Error: %v", try, utils.RECONNECTTRIES, err)
+			continue
+		}
+		c.conn.swap(conn)
+		err = c.sendHello()
+		if err == nil {
+			c.numStations, err = c.receiveWelcome()
+		}
+		if err != nil {
+			conn.Close()
+			replyChan <- fmt.Sprintf("Could not reconnect to the server (try %d of %d). Error: %v", try, utils.RECONNECTTRIES, err)
+			continue
+		}
+		if c.heartbeat.Load() > 0 {
+			err = c.startHeartbeats()
+			if err != nil {
+				conn.Close()
+				replyChan <- fmt.Sprintf("Could not reconnect to the server (try %d of %d). Error: %v", try, utils.RECONNECTTRIES, err)
+				continue
+			}
+		}
+		message := fmt.Sprintf("Reconnected to the server. It has %d stations", c.numStations)
+		if station := c.station.Load(); station >= 0 {
+			err = c.SetStation(uint16(station))
+			if err == nil {
+				message += fmt.Sprintf(". Tuning back in to station %d", station)
+			}
+		}
+		replyChan <- message
+		return true
+	}
+	replyChan <- "Could not reconnect to the server. Giving up"
+	return false
+}
+
 // receiveWelcome receives the welcome message from the client
 func (c *Client) receiveWelcome() (uint16, error) {
 	buffer := make([]byte, utils.BUFFSIZE)
@@ -111,23 +556,56 @@ func (c *Client) receiveWelcome() (uint16, error) {
 
 // ReceiveReply receives a reply from the server
 func (c *Client) ReceiveReply(replyChan chan string) {
+	defer close(c.doneChan)
 	for {
 		select {
 		case <-c.exitChan:
 			return
 		default:
+			if c.extraCredit && c.heartbeat.Load() > 0 {
+				// the server answers every ping so silence means it is gone
+				c.conn.SetReadDeadline(time.Now().Add(c.heartbeat.Load() * utils.HEARTBEATMISSES))
+			}
 			buffer := make([]byte, utils.BUFFSIZE)
-			_, err := c.conn.Read(buffer)
+			n, err := c.conn.Read(buffer)
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				c.conn.Close()
+				replyChan <- fmt.Sprintf("invalid command: The server missed %d heartbeats", utils.HEARTBEATMISSES)
+				return
+			}
 			if err != nil {
 				c.conn.Close()
+				replyChan <- fmt.Sprintf("Lost the connection to the server. Error: %v", err)
 				return
 			}
+			if c.extraCredit {
+				// a pong can arrive stuck to the reply sent after it
+				pongs := 0
+				for n-pongs >= 5 && utils.ReplyType(buffer[pongs]) == utils.Pong {
+					c.keepUpWith(time.Duration(binary.BigEndian.Uint32(buffer[pongs+1:pongs+5])) * time.Millisecond)
+					pongs += 5
+				}
+				if pongs == n {
+					continue
+				}
+				buffer, n = buffer[pongs:], n-pongs
+			}
 			replyType := utils.ReplyType(uint8(buffer[0]))
 			var message string
 			switch replyType {
 			case utils.Announce:
 				songNameLength := uint8(buffer[1])
 				message = fmt.Sprintf("New song announced: %s", string(buffer[2:2+songNameLength]))
+			case utils.Interstitial:
+				if c.extraCredit {
+					clipNameLength := uint8(buffer[1])
+					message = fmt.Sprintf("Interstitial playing: %s", string(buffer[2:2+clipNameLength]))
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
 			case utils.InvalidCommand:
 				replyLength := uint8(buffer[1])
 				message = fmt.Sprintf("invalid command: %s", string(buffer[2:2+replyLength]))
@@ -151,6 +629,276 @@ func (c *Client) ReceiveReply(replyChan chan string) {
 					replyChan <- message
 					return
 				}
+			case utils.ShowInfo:
+				if c.extraCredit {
+					currentSize := int(buffer[1])
+					nextSize := int(buffer[2])
+					nextIn := binary.BigEndian.Uint16(buffer[3:5])
+					currentShow := string(buffer[5 : 5+currentSize])
+					nextShow := string(buffer[5+currentSize : 5+currentSize+nextSize])
+					if currentShow == "" {
+						currentShow = "regular programming"
+					}
+					message = fmt.Sprintf("Now on: %s", currentShow)
+					if nextShow != "" {
+						now := time.Now()
+						nextStart := now.Add(time.Duration(nextIn) * time.Minute)
+						if nextStart.YearDay() == now.YearDay() {
+							message += fmt.Sprintf(". Next: %s at %s", nextShow, nextStart.Format("15:04"))
+						} else {
+							message += fmt.Sprintf(". Next: %s on %s", nextShow, nextStart.Format("Mon 15:04"))
+						}
+					}
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
+			case utils.AdminReply:
+				if c.extraCredit {
+					replySize := binary.BigEndian.Uint16(buffer[1:3])
+					message = fmt.Sprintf("Admin: %s", string(buffer[3:3+replySize]))
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
+			case utils.StationPaused:
+				if c.extraCredit {
+					station := binary.BigEndian.Uint16(buffer[1:3])
+					message = fmt.Sprintf("Station %d is paused. Stay tuned", station)
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
+			case utils.StationResumed:
+				if c.extraCredit {
+					station := binary.BigEndian.Uint16(buffer[1:3])
+					message = fmt.Sprintf("Station %d is back on", station)
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
+			case utils.StationList:
+				if c.extraCredit {
+					stations, err := utils.ParseStationListMessage(buffer)
+					if err != nil {
+						message = fmt.Sprintf("Could not read the station list. Error: %v", err)
+					} else {
+						message = c.handleStationList(stations)
+					}
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
+			case utils.AllStationSongs:
+				if c.extraCredit {
+					table, err := c.readAllStationSongs(buffer[:n])
+					if err != nil {
+						message = fmt.Sprintf("Could not read the songs of every station. Error: %v", err)
+					} else {
+						message = table
+					}
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
+			case utils.SearchResults:
+				if c.extraCredit {
+					results, err := c.readSearchResults(buffer[:n])
+					if err != nil {
+						message = fmt.Sprintf("Could not read the search results. Error: %v", err)
+					} else {
+						message = results
+					}
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
+			case utils.RequestQueued:
+				if c.extraCredit {
+					station := binary.BigEndian.Uint16(buffer[1:3])
+					position := binary.BigEndian.Uint16(buffer[3:5])
+					songNameLength := int(buffer[5])
+					message = fmt.Sprintf("Requested %s on station %d. It is number %d in line", string(buffer[6:6+songNameLength]), station, position)
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
+			case utils.RequestPlaying:
+				if c.extraCredit {
+					station := binary.BigEndian.Uint16(buffer[1:3])
+					songNameLength := int(buffer[3])
+					message = fmt.Sprintf("Your request %s is playing on station %d", string(buffer[4:4+songNameLength]), station)
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
+			case utils.RequestDenied:
+				if c.extraCredit {
+					reasonLength := int(buffer[1])
+					message = fmt.Sprintf("Could not request the song. %s", string(buffer[2:2+reasonLength]))
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
+			case utils.SkipVotes:
+				if c.extraCredit {
+					station := binary.BigEndian.Uint16(buffer[1:3])
+					votes := binary.BigEndian.Uint16(buffer[3:5])
+					needed := binary.BigEndian.Uint16(buffer[5:7])
+					message = fmt.Sprintf("%d of %d votes to skip the song on station %d", votes, needed, station)
+					if votes >= needed {
+						message += ". Skipping it"
+					}
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
+			case utils.VoteDenied:
+				if c.extraCredit {
+					reasonLength := int(buffer[1])
+					message = fmt.Sprintf("Could not vote to skip. %s", string(buffer[2:2+reasonLength]))
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
+			case utils.ChatReply:
+				if c.extraCredit {
+					station := binary.BigEndian.Uint16(buffer[1:3])
+					nicknameSize := int(buffer[3])
+					textSize := int(buffer[4])
+					nickname := string(buffer[5 : 5+nicknameSize])
+					text := string(buffer[5+nicknameSize : 5+nicknameSize+textSize])
+					message = fmt.Sprintf("[station %d] %s: %s", station, nickname, text)
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
+			case utils.ChatDenied:
+				if c.extraCredit {
+					reasonLength := int(buffer[1])
+					message = fmt.Sprintf("Could not send the chat message. %s", string(buffer[2:2+reasonLength]))
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
+			case utils.StoppedListening:
+				if c.extraCredit {
+					station := binary.BigEndian.Uint16(buffer[1:3])
+					message = fmt.Sprintf("Stopped listening to station %d", station)
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
+			case utils.StopDenied:
+				if c.extraCredit {
+					reasonLength := int(buffer[1])
+					message = fmt.Sprintf("Could not stop listening. %s", string(buffer[2:2+reasonLength]))
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
+			case utils.WatchedSong:
+				if c.extraCredit {
+					station := binary.BigEndian.Uint16(buffer[1:3])
+					songNameLength := int(buffer[3])
+					message = fmt.Sprintf("[station %d] now playing: %s", station, string(buffer[4:4+songNameLength]))
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
+			case utils.Watching:
+				if c.extraCredit {
+					watching, err := c.readWatching(buffer[:n])
+					if err != nil {
+						message = fmt.Sprintf("Could not read the watched stations. Error: %v", err)
+					} else {
+						message = watching
+					}
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
+			case utils.WatchDenied:
+				if c.extraCredit {
+					reasonLength := int(buffer[1])
+					message = fmt.Sprintf("Could not change the stations you watch. %s", string(buffer[2:2+reasonLength]))
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
+			case utils.StationDraining:
+				if c.extraCredit {
+					station := binary.BigEndian.Uint16(buffer[1:3])
+					secondsLeft := binary.BigEndian.Uint16(buffer[3:5])
+					message = fmt.Sprintf("Station %d is shutting down in %d seconds", station, secondsLeft)
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
+			case utils.ServerShutdown:
+				if c.extraCredit {
+					reconnectAfter := binary.BigEndian.Uint16(buffer[1:3])
+					reasonLength := int(buffer[3])
+					message = fmt.Sprintf("The server is going away. %s", string(buffer[4:4+reasonLength]))
+					if reconnectAfter > 0 {
+						message += fmt.Sprintf(". It will be back in %d seconds", reconnectAfter)
+					}
+					c.conn.Close()
+					if !c.reconnect || reconnectAfter == 0 {
+						replyChan <- message
+						return
+					}
+					replyChan <- message + ". Reconnecting then"
+					if !c.reconnectAfter(time.Duration(reconnectAfter)*time.Second, replyChan) {
+						return
+					}
+					continue
+				} else {
+					message = fmt.Sprintf("invalid command: Could not recognize reply type from server")
+					c.conn.Close()
+					replyChan <- message
+					return
+				}
 			case utils.NewStation:
 				if c.extraCredit {
 					station := binary.BigEndian.Uint16(buffer[1:3])
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// serverConn is the tcp connection to the server. It is swapped for a new connection when the client reconnects
+type serverConn struct {
+	conn  *net.TCPConn
+	mutex sync.RWMutex
+}
+
+// createServerConn creates a serverConn
+func createServerConn(conn *net.TCPConn) *serverConn {
+	return &serverConn{
+		conn: conn,
+	}
+}
+
+// get returns the current connection
+func (s *serverConn) get() *net.TCPConn {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.conn
+}
+
+// swap replaces the connection with a new one
+func (s *serverConn) swap(conn *net.TCPConn) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.conn = conn
+}
+
+// Read reads from the current connection
+func (s *serverConn) Read(buffer []byte) (int, error) {
+	return s.get().Read(buffer)
+}
+
+// Write writes to the current connection
+func (s *serverConn) Write(buffer []byte) (int, error) {
+	return s.get().Write(buffer)
+}
+
+// Close closes the current connection
+func (s *serverConn) Close() error {
+	return s.get().Close()
+}
+
+// SetReadDeadline sets the read deadline of the current connection
+func (s *serverConn) SetReadDeadline(deadline time.Time) error {
+	return s.get().SetReadDeadline(deadline)
+}
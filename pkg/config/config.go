@@ -0,0 +1,383 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	LivePrefix       = "live:"
+	DefaultLiveTitle = "Live"
+)
+
+type Config struct {
+	// address the server listens for clients on, e.g. ":8888"
+	Listen string `json:"listen"`
+	// address source clients push live audio to. Empty disables source clients
+	SourceListen   string `json:"sourceListen"`
+	SourcePassword string `json:"sourcePassword"`
+	// password clients send with admin commands. Empty disables admin commands
+	AdminPassword string `json:"adminPassword"`
+	// file the playback position of every station is saved to and restored from. Empty disables saving
+	StateFile string `json:"stateFile"`
+	// milliseconds between the heartbeats a client has to send once it starts sending them. 0 uses the default
+	HeartbeatInterval int `json:"heartbeatInterval"`
+	// heartbeats in a row a client can miss before it is disconnected. 0 uses the default
+	HeartbeatMisses int `json:"heartbeatMisses"`
+	// milliseconds a client has to say hello after connecting. 0 uses the default
+	HelloTimeout int `json:"helloTimeout"`
+	// milliseconds a client has to tune in to or watch a station after saying hello. 0 uses the default
+	IdleTimeout int             `json:"idleTimeout"`
+	Features    Features        `json:"features"`
+	Stations    []StationConfig `json:"stations"`
+}
+
+type Features struct {
+	ExtraCredit bool `json:"extraCredit"`
+}
+
+type StationConfig struct {
+	// number clients tune in with. Stations without one are numbered in order after the last station
+	ID   *int   `json:"id"`
+	Name string `json:"name"`
+	// what the station plays, shown to clients listing the stations
+	Description string   `json:"description"`
+	Songs       []string `json:"songs"`
+	// .m3u, .m3u8 or .pls playlist whose songs are played after the listed songs
+	Playlist string `json:"playlist"`
+	// directory whose files are played. The directory is rescanned for new and removed files
+	Directory string `json:"directory"`
+	// live feed of the station: stdin, fifo:[path] or udp:[port]
+	Live      string `json:"live"`
+	LiveTitle string `json:"liveTitle"`
+	// milliseconds between song chunks. 0 uses the default pace
+	SleepTime int `json:"sleepTime"`
+	// most listeners allowed on the station at once. 0 means no limit
+	MaxListeners int `json:"maxListeners"`
+	// play mode of the station: sequential, shuffle, random, once or clock
+	Mode string `json:"mode"`
+	// with random play, how many of the last songs played can't be picked again
+	NoRepeat int `json:"noRepeat"`
+	// same as the shuffle mode. Kept for older configs
+	Shuffle bool `json:"shuffle"`
+	// songs tagged by category, e.g. current, gold or jingle. Tagged songs are added to the station songs
+	Categories map[string][]CategorySong `json:"categories"`
+	// order the clock mode plays categories in, e.g. current, gold, current, jingle. Setting a clock defaults the mode to clock
+	Clock []string `json:"clock"`
+	// shows that replace the songs of the station during their time windows
+	Schedule []ShowConfig `json:"schedule"`
+	// clips played between songs, e.g. station ids. They aren't songs of the station
+	Interstitials InterstitialConfig `json:"interstitials"`
+	// clip looped when none of the songs can be played. Empty plays silence
+	Fallback string `json:"fallback"`
+	// fraction of the listeners that have to vote to skip a song. 0 uses half the listeners
+	SkipFraction float64 `json:"skipFraction"`
+}
+
+type InterstitialConfig struct {
+	// clips picked at random each time one is due
+	Clips []string `json:"clips"`
+	// plays a clip after this many songs. 0 doesn't count songs
+	EverySongs int `json:"everySongs"`
+	// plays a clip at the first song boundary this many minutes after the last clip. 0 doesn't time clips
+	EveryMinutes int `json:"everyMinutes"`
+}
+
+type ShowConfig struct {
+	Name string `json:"name"`
+	// time of day the show starts and ends as HH:MM. A show that ends before it starts runs past midnight
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// days the show starts on, e.g. mon or sat. Empty means every day
+	Days      []string `json:"days"`
+	Songs     []string `json:"songs"`
+	Playlist  string   `json:"playlist"`
+	Directory string   `json:"directory"`
+}
+
+type CategorySong struct {
+	Song string `json:"song"`
+	// how likely the song is to be picked in the clock and random modes. 0 counts as 1
+	Weight int `json:"weight"`
+}
+
+// Load reads a json config file
+func Load(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config %s. Error: %v", path, err)
+	}
+	config := &Config{}
+	err = json.Unmarshal(data, config)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse config %s. Error: %v", path, err)
+	}
+	err = config.validate()
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// FromArgs builds a config from the command line. With extra credit each file can list comma separated songs
+func FromArgs(port string, files []string, extraCredit bool) (*Config, error) {
+	config := &Config{
+		Listen:   ":" + port,
+		Features: Features{ExtraCredit: extraCredit},
+		Stations: make([]StationConfig, 0),
+	}
+	for _, file := range files {
+		entries := []string{file}
+		if extraCredit {
+			entries = strings.Split(file, ",")
+		} else if !IsPlaylist(file) && !isDirectory(file) {
+			config.Stations = append(config.Stations, StationConfig{Songs: entries})
+			continue
+		}
+		station, err := StationFromEntries("", entries)
+		if err != nil {
+			return nil, err
+		}
+		config.Stations = append(config.Stations, station)
+	}
+	err := config.validate()
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// StationFromEntries builds a station config from song files. A live:[feed]=[title] entry sets the live feed,
+// and a playlist or directory entry sets the playlist or directory of the station
+func StationFromEntries(name string, entries []string) (StationConfig, error) {
+	station := StationConfig{
+		Name:  name,
+		Songs: make([]string, 0),
+	}
+	for _, entry := range entries {
+		switch {
+		case IsLiveEntry(entry):
+			if station.Live != "" {
+				return station, fmt.Errorf("A station can only have one live feed")
+			}
+			station.Live, station.LiveTitle = ParseLiveEntry(entry)
+		case IsPlaylist(entry):
+			if station.Playlist != "" {
+				return station, fmt.Errorf("A station can only have one playlist")
+			}
+			station.Playlist = entry
+		case isDirectory(entry):
+			if station.Directory != "" {
+				return station, fmt.Errorf("A station can only have one directory")
+			}
+			station.Directory = entry
+		default:
+			station.Songs = append(station.Songs, entry)
+		}
+	}
+	return station, nil
+}
+
+// isDirectory returns true if the path is a directory
+func isDirectory(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// IsPlaylist returns true if the file is an .m3u, .m3u8 or .pls playlist
+func IsPlaylist(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".m3u", ".m3u8", ".pls":
+		return true
+	}
+	return false
+}
+
+// IsLiveEntry returns true if the station entry describes a live feed rather than a song file
+func IsLiveEntry(entry string) bool {
+	return strings.HasPrefix(entry, LivePrefix)
+}
+
+// ParseLiveEntry splits a live entry of the form live:[feed]=[title] into the feed and its title
+func ParseLiveEntry(entry string) (string, string) {
+	feed := strings.TrimPrefix(entry, LivePrefix)
+	title := DefaultLiveTitle
+	if idx := strings.Index(feed, "="); idx >= 0 {
+		feed, title = feed[:idx], feed[idx+1:]
+	}
+	return feed, title
+}
+
+// UsesStdin returns true if any station takes its live feed from stdin
+func (c *Config) UsesStdin() bool {
+	for _, station := range c.Stations {
+		if station.Live == "stdin" {
+			return true
+		}
+	}
+	return false
+}
+
+// validate fills in defaults and checks the config makes sense
+func (c *Config) validate() error {
+	if c.Listen == "" {
+		return fmt.Errorf("config needs an address to listen on")
+	}
+	if c.SourceListen != "" && c.SourcePassword == "" {
+		return fmt.Errorf("source clients need a password")
+	}
+	if c.HeartbeatInterval < 0 || c.HeartbeatMisses < 0 || c.HelloTimeout < 0 || c.IdleTimeout < 0 {
+		return fmt.Errorf("heartbeats and timeouts cannot be negative")
+	}
+	names := make(map[string]bool)
+	ids := make(map[int]bool)
+	for idx := range c.Stations {
+		station := &c.Stations[idx]
+		if station.Name == "" {
+			station.Name = fmt.Sprintf("station-%d", idx)
+		}
+		if names[station.Name] {
+			return fmt.Errorf("station name %s is used more than once", station.Name)
+		}
+		names[station.Name] = true
+		if station.ID != nil {
+			if *station.ID < 0 || *station.ID > math.MaxUint16 {
+				return fmt.Errorf("station %s: id %d is not between 0 and %d", station.Name, *station.ID, math.MaxUint16)
+			}
+			if ids[*station.ID] {
+				return fmt.Errorf("station id %d is used more than once", *station.ID)
+			}
+			ids[*station.ID] = true
+		}
+		err := station.validate()
+		if err != nil {
+			return fmt.Errorf("station %s: %v", station.Name, err)
+		}
+	}
+	return nil
+}
+
+// validate fills in defaults and checks the station config makes sense
+func (sc *StationConfig) validate() error {
+	if len(sc.Songs) == 0 && len(sc.Categories) == 0 && len(sc.Schedule) == 0 && sc.Live == "" && sc.Playlist == "" && sc.Directory == "" {
+		return fmt.Errorf("a station needs songs, a playlist, a directory, a schedule or a live feed")
+	}
+	if sc.Playlist != "" && !IsPlaylist(sc.Playlist) {
+		return fmt.Errorf("playlist %s is not an .m3u, .m3u8 or .pls file", sc.Playlist)
+	}
+	if sc.Live != "" && sc.LiveTitle == "" {
+		sc.LiveTitle = DefaultLiveTitle
+	}
+	if sc.SleepTime < 0 {
+		return fmt.Errorf("sleep time cannot be negative")
+	}
+	if sc.MaxListeners < 0 {
+		return fmt.Errorf("max listeners cannot be negative")
+	}
+	if sc.NoRepeat < 0 {
+		return fmt.Errorf("no repeat window cannot be negative")
+	}
+	if sc.SkipFraction < 0 || sc.SkipFraction > 1 {
+		return fmt.Errorf("skip fraction must be between 0 and 1")
+	}
+	// a song has one weight on the station, so a song in several categories needs the same weight in each
+	weights := make(map[string]int)
+	for category, songs := range sc.Categories {
+		for _, song := range songs {
+			if song.Song == "" {
+				return fmt.Errorf("category %s has a song without a file", category)
+			}
+			if song.Weight < 0 {
+				return fmt.Errorf("song %s in category %s cannot have a negative weight", song.Song, category)
+			}
+			weight := song.Weight
+			if weight == 0 {
+				weight = 1
+			}
+			if other, ok := weights[song.Song]; ok && other != weight {
+				return fmt.Errorf("song %s has different weights in different categories", song.Song)
+			}
+			weights[song.Song] = weight
+		}
+	}
+	for _, category := range sc.Clock {
+		if len(sc.Categories[category]) == 0 {
+			return fmt.Errorf("clock category %s has no songs", category)
+		}
+	}
+	if sc.Interstitials.EverySongs < 0 || sc.Interstitials.EveryMinutes < 0 {
+		return fmt.Errorf("interstitials cannot be played a negative number of songs or minutes apart")
+	}
+	if len(sc.Interstitials.Clips) == 0 && (sc.Interstitials.EverySongs > 0 || sc.Interstitials.EveryMinutes > 0) {
+		return fmt.Errorf("interstitials need clips to play")
+	}
+	shows := make(map[string]bool)
+	for _, show := range sc.Schedule {
+		if shows[show.Name] {
+			return fmt.Errorf("show name %s is used more than once", show.Name)
+		}
+		shows[show.Name] = true
+		err := show.validate()
+		if err != nil {
+			return fmt.Errorf("show %s: %v", show.Name, err)
+		}
+	}
+	return nil
+}
+
+// validate checks the show config makes sense
+func (show *ShowConfig) validate() error {
+	if show.Name == "" {
+		return fmt.Errorf("a show needs a name")
+	}
+	if len(show.Songs) == 0 && show.Playlist == "" && show.Directory == "" {
+		return fmt.Errorf("a show needs songs, a playlist or a directory")
+	}
+	if show.Playlist != "" && !IsPlaylist(show.Playlist) {
+		return fmt.Errorf("playlist %s is not an .m3u, .m3u8 or .pls file", show.Playlist)
+	}
+	start, err := ParseTimeOfDay(show.Start)
+	if err != nil {
+		return err
+	}
+	end, err := ParseTimeOfDay(show.End)
+	if err != nil {
+		return err
+	}
+	if start == end {
+		return fmt.Errorf("a show cannot start and end at the same time")
+	}
+	for _, day := range show.Days {
+		_, err := ParseDay(day)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseTimeOfDay returns the minutes after midnight of a time of day written as HH:MM
+func ParseTimeOfDay(value string) (int, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("time %s is not of the form HH:MM", value)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// ParseDay returns the weekday named by its first three letters, e.g. mon
+func ParseDay(name string) (time.Weekday, error) {
+	name = strings.ToLower(name)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if len(name) >= 3 && strings.HasPrefix(strings.ToLower(day.String()), name) {
+			return day, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("day %s not recognized", name)
+}
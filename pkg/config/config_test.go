@@ -0,0 +1,257 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLiveEntry(t *testing.T) {
+	if IsLiveEntry("../../mp3/tinyfile") {
+		t.Errorf("expected: false, received: true")
+	}
+	if !IsLiveEntry("live:stdin") {
+		t.Errorf("expected: true, received: false")
+	}
+	feed, title := ParseLiveEntry("live:udp:9000=Morning Show")
+	if feed != "udp:9000" {
+		t.Errorf("expected: udp:9000, received: %s", feed)
+	}
+	if title != "Morning Show" {
+		t.Errorf("expected: Morning Show, received: %s", title)
+	}
+	_, title = ParseLiveEntry("live:stdin")
+	if title != DefaultLiveTitle {
+		t.Errorf("expected: %s, received: %s", DefaultLiveTitle, title)
+	}
+}
+
+func TestStationFromEntries(t *testing.T) {
+	_, err := StationFromEntries("news", []string{"live:stdin", "live:udp:9000"})
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	station, err := StationFromEntries("news", []string{"a.mp3", "live:udp:9000=News", "b.mp3"})
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	if len(station.Songs) != 2 {
+		t.Errorf("expected: 2, received: %d", len(station.Songs))
+	}
+	if station.Live != "udp:9000" {
+		t.Errorf("expected: udp:9000, received: %s", station.Live)
+	}
+	if station.LiveTitle != "News" {
+		t.Errorf("expected: News, received: %s", station.LiveTitle)
+	}
+}
+
+func TestFromArgs(t *testing.T) {
+	cfg, err := FromArgs("8888", []string{"a.mp3,b.mp3", "c.mp3"}, false)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	if cfg.Listen != ":8888" {
+		t.Errorf("expected: :8888, received: %s", cfg.Listen)
+	}
+	if len(cfg.Stations) != 2 || len(cfg.Stations[0].Songs) != 1 {
+		t.Errorf("expected: 2 stations with 1 song each, received: %v", cfg.Stations)
+	}
+	cfg, err = FromArgs("8888", []string{"a.mp3,b.mp3", "live:stdin"}, true)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	if !cfg.Features.ExtraCredit {
+		t.Errorf("expected: true, received: false")
+	}
+	if len(cfg.Stations[0].Songs) != 2 {
+		t.Errorf("expected: 2, received: %d", len(cfg.Stations[0].Songs))
+	}
+	if cfg.Stations[1].Name != "station-1" {
+		t.Errorf("expected: station-1, received: %s", cfg.Stations[1].Name)
+	}
+	if !cfg.UsesStdin() {
+		t.Errorf("expected: true, received: false")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snowcast")
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.json")
+	_, err = Load(path)
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	ioutil.WriteFile(path, []byte(`{"listen": ":8888", "stations": [{"name": "a", "songs": ["x"]}, {"name": "a", "songs": ["y"]}]}`), 0644)
+	_, err = Load(path)
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	ioutil.WriteFile(path, []byte(`{"listen": ":8888", "stations": [{"name": "empty"}]}`), 0644)
+	_, err = Load(path)
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	ioutil.WriteFile(path, []byte(`{
+		"listen": ":8888",
+		"features": {"extraCredit": true},
+		"stations": [
+			{"name": "focus", "songs": ["x", "y"], "sleepTime": 500, "maxListeners": 10, "shuffle": true},
+			{"name": "morning", "live": "udp:9000"}
+		]
+	}`), 0644)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	if len(cfg.Stations) != 2 {
+		t.Errorf("expected: 2, received: %d", len(cfg.Stations))
+	}
+	focus := cfg.Stations[0]
+	if focus.SleepTime != 500 || focus.MaxListeners != 10 || !focus.Shuffle {
+		t.Errorf("expected: settings to load, received: %v", focus)
+	}
+	if cfg.Stations[1].LiveTitle != DefaultLiveTitle {
+		t.Errorf("expected: %s, received: %s", DefaultLiveTitle, cfg.Stations[1].LiveTitle)
+	}
+}
+
+func TestFromArgsPlaylists(t *testing.T) {
+	cfg, err := FromArgs("8888", []string{"list.m3u", "../../mp3", "a.mp3"}, false)
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	if cfg.Stations[0].Playlist != "list.m3u" {
+		t.Errorf("expected: list.m3u, received: %s", cfg.Stations[0].Playlist)
+	}
+	if cfg.Stations[1].Directory != "../../mp3" {
+		t.Errorf("expected: ../../mp3, received: %s", cfg.Stations[1].Directory)
+	}
+	if len(cfg.Stations[2].Songs) != 1 {
+		t.Errorf("expected: 1, received: %d", len(cfg.Stations[2].Songs))
+	}
+	_, err = StationFromEntries("", []string{"a.pls", "b.m3u"})
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+}
+
+func TestCategories(t *testing.T) {
+	station := StationConfig{
+		Categories: map[string][]CategorySong{"current": {{Song: "a.mp3"}}},
+		Clock:      []string{"current", "gold"},
+	}
+	if station.validate() == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	station.Categories["gold"] = []CategorySong{{Song: "b.mp3", Weight: -1}}
+	if station.validate() == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	station.Categories["gold"][0].Weight = 2
+	if err := station.validate(); err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	// a song can't weigh differently in two categories
+	station.Categories["gold"] = append(station.Categories["gold"], CategorySong{Song: "a.mp3", Weight: 3})
+	if station.validate() == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	station.Categories["gold"][1].Weight = 1
+	if err := station.validate(); err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	station := StationConfig{
+		Songs:    []string{"a.mp3"},
+		Schedule: []ShowConfig{{Name: "focus", Start: "9am", End: "12:00", Songs: []string{"b.mp3"}}},
+	}
+	if station.validate() == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	station.Schedule[0].Start = "09:00"
+	station.Schedule[0].Days = []string{"someday"}
+	if station.validate() == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	station.Schedule[0].Days = []string{"mon", "Friday"}
+	if err := station.validate(); err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	minutes, _ := ParseTimeOfDay("13:30")
+	if minutes != 13*60+30 {
+		t.Errorf("expected: %d, received: %d", 13*60+30, minutes)
+	}
+}
+
+func TestInterstitials(t *testing.T) {
+	station := StationConfig{
+		Songs:         []string{"a.mp3"},
+		Interstitials: InterstitialConfig{EverySongs: 3},
+	}
+	if station.validate() == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	station.Interstitials.Clips = []string{"id.mp3"}
+	station.Interstitials.EveryMinutes = -1
+	if station.validate() == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	station.Interstitials.EveryMinutes = 15
+	if err := station.validate(); err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+}
+
+func TestStationIDs(t *testing.T) {
+	id := 7
+	other := 7
+	cfg := &Config{
+		Listen: ":8888",
+		Stations: []StationConfig{
+			{Name: "a", Songs: []string{"x"}, ID: &id},
+			{Name: "b", Songs: []string{"y"}, ID: &other},
+		},
+	}
+	if cfg.validate() == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	other = 70000
+	if cfg.validate() == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	other = 3
+	if err := cfg.validate(); err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+}
+
+func TestSkipFraction(t *testing.T) {
+	station := StationConfig{Songs: []string{"a.mp3"}, SkipFraction: 1.5}
+	if station.validate() == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	station.SkipFraction = 0.75
+	if err := station.validate(); err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+}
+
+func TestTimeouts(t *testing.T) {
+	cfg := Config{Listen: ":8888", HeartbeatInterval: 1000, HeartbeatMisses: -1}
+	if cfg.validate() == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	cfg.HeartbeatMisses = 2
+	cfg.IdleTimeout = 30000
+	if err := cfg.validate(); err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package radio
+
+import (
+	"reflect"
+
+	"github.com/IMaloney/snowcast/pkg/config"
+)
+
+// SetClock sets the song categories of the station and the order the clock mode plays them in
+func (s *Station) SetClock(categories map[string][]config.CategorySong, clock []string) {
+	names := make(map[string][]string)
+	weights := make(map[string]int)
+	for category, songs := range categories {
+		for _, song := range songs {
+			names[category] = append(names[category], song.Song)
+			if song.Weight > weights[song.Song] {
+				weights[song.Song] = song.Weight
+			}
+		}
+	}
+	if clock == nil {
+		clock = make([]string, 0)
+	}
+	s.songsMutex.Lock()
+	defer s.songsMutex.Unlock()
+	if reflect.DeepEqual(names, s.categories) && reflect.DeepEqual(weights, s.weights) && reflect.DeepEqual(clock, s.clock) {
+		return
+	}
+	s.categories = names
+	s.weights = weights
+	s.clock = clock
+	s.clockSlot = 0
+	// songs planned from the old clock no longer follow it
+	if s.mode == Clock || s.mode == Random {
+		s.upcoming = make([]*Song, 0)
+	}
+}
+
+// GetClock returns the order the clock mode plays categories in
+func (s *Station) GetClock() []string {
+	s.songsMutex.RLock()
+	defer s.songsMutex.RUnlock()
+	return append(make([]string, 0), s.clock...)
+}
+
+// planClock plans a song from the next category of the clock. Categories without songs on the station are skipped,
+// and the songs are played in order if none of the categories have songs. songsMutex must be held
+func (s *Station) planClock(last int) {
+	for tries := 0; tries < len(s.clock); tries++ {
+		category := s.clock[s.clockSlot]
+		s.clockSlot = (s.clockSlot + 1) % len(s.clock)
+		songs := s.categorySongs(category)
+		if len(songs) > 0 {
+			s.upcoming = append(s.upcoming, s.pickRandom(songs))
+			return
+		}
+	}
+	s.upcoming = append(s.upcoming, s.songs[(last+1)%len(s.songs)])
+}
+
+// categorySongs returns the songs of a category that are on the station. songsMutex must be held
+func (s *Station) categorySongs(category string) []*Song {
+	onStation := make(map[string]*Song)
+	for _, song := range s.songs {
+		onStation[song.GetSongName()] = song
+	}
+	songs := make([]*Song, 0)
+	for _, name := range s.categories[category] {
+		if song, ok := onStation[name]; ok {
+			songs = append(songs, song)
+		}
+	}
+	return songs
+}
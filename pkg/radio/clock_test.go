@@ -0,0 +1,58 @@
+package radio
+
+import (
+	"testing"
+
+	"github.com/IMaloney/snowcast/pkg/config"
+)
+
+func TestClockUpcoming(t *testing.T) {
+	current := "../../mp3/tinyfile"
+	gold := "../../mp3/mediumfile"
+	jingle := "../../mp3/FX-Impact193.mp3"
+	station, err := CreateStationFromConfig(config.StationConfig{
+		Name: "clock",
+		Categories: map[string][]config.CategorySong{
+			"current": {{Song: current, Weight: 3}},
+			"gold":    {{Song: gold}},
+			"jingle":  {{Song: jingle}},
+		},
+		Clock: []string{"current", "gold", "current", "jingle"},
+	})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer station.quitStation()
+	if mode, _ := station.GetPlayMode(); mode != Clock {
+		t.Errorf("expected: clock, received: %s", mode)
+	}
+	if len(station.GetStationSongs()) != 3 {
+		t.Errorf("expected: 3, received: %d", len(station.GetStationSongs()))
+	}
+	expected := []string{current, gold, current, jingle, current, gold}
+	upcoming := station.GetUpcomingSongs(len(expected))
+	for idx := range expected {
+		if upcoming[idx] != expected[idx] {
+			t.Errorf("expected: %s, received: %s", expected[idx], upcoming[idx])
+		}
+	}
+	// categories without songs on the station are skipped
+	station.RemoveSong(gold)
+	station.SetPlayMode(Clock, 0)
+	for _, song := range station.GetUpcomingSongs(4) {
+		if song == gold {
+			t.Errorf("expected: no %s, received: %s", gold, song)
+		}
+	}
+}
+
+func TestClockNeedsClock(t *testing.T) {
+	_, err := CreateStationFromConfig(config.StationConfig{
+		Name:  "clock",
+		Songs: []string{"../../mp3/tinyfile"},
+		Mode:  "clock",
+	})
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+}
@@ -0,0 +1,183 @@
+package radio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/IMaloney/snowcast/pkg/utils"
+)
+
+const (
+	// every song plays
+	HealthOK = "ok"
+	// some songs can't be played and are skipped
+	HealthDegraded = "degraded"
+	// no song can be played so the station plays its fallback clip or silence
+	HealthSilent = "silent"
+	// the station has no songs
+	HealthIdle = "idle"
+	// announced while a station without a fallback clip plays silence
+	SilenceTitle = "Silence"
+)
+
+// SetFallback sets the clip the station loops when none of its songs can be played. Empty plays silence
+func (s *Station) SetFallback(name string) error {
+	s.songsMutex.RLock()
+	current := ""
+	if s.fallback != nil {
+		current = s.fallback.GetSongName()
+	}
+	s.songsMutex.RUnlock()
+	if name == current {
+		return nil
+	}
+	var fallback *Song
+	if name != "" {
+		var err error
+		fallback, err = CreateSong(name)
+		if err != nil {
+			return fmt.Errorf("Could not open fallback clip %s. Error: %v", name, err)
+		}
+	}
+	s.songsMutex.Lock()
+	old := s.fallback
+	s.fallback = fallback
+	s.songsMutex.Unlock()
+	if old != nil {
+		old.EndSong()
+	}
+	return nil
+}
+
+// GetHealth returns the health of the station and the songs it can't play
+func (s *Station) GetHealth() (string, []string) {
+	s.songsMutex.RLock()
+	defer s.songsMutex.RUnlock()
+	unplayable := make([]string, 0)
+	for song := range s.unplayable {
+		unplayable = append(unplayable, song.GetSongName())
+	}
+	sort.Strings(unplayable)
+	switch {
+	case len(s.songs) == 0 && s.onAir.Load():
+		return HealthOK, unplayable
+	case len(s.songs) == 0:
+		return HealthIdle, unplayable
+	case s.silent:
+		return HealthSilent, unplayable
+	case len(unplayable) > 0:
+		return HealthDegraded, unplayable
+	}
+	return HealthOK, unplayable
+}
+
+// warn passes a warning on to whoever runs the station. Warnings are dropped if nobody keeps up with them
+func (s *Station) warn(warning string) {
+	select {
+	case s.warnChan <- warning:
+	default:
+	}
+}
+
+// markUnplayable sets a song aside until it can be opened again
+func (s *Station) markUnplayable(song *Song, reason string) {
+	s.songsMutex.Lock()
+	s.unplayable[song] = time.Now()
+	s.songsMutex.Unlock()
+	s.warn(fmt.Sprintf("station %s: skipping %s. %s", s.name, song.GetSongName(), reason))
+}
+
+// isUnplayable returns true if the song has been set aside. songsMutex must be held
+func (s *Station) isUnplayable(song *Song) bool {
+	_, ok := s.unplayable[song]
+	return ok
+}
+
+// nextPlayable returns the first song after the current song that can be played, nil if none can.
+// songsMutex must be held
+func (s *Station) nextPlayable() *Song {
+	for offset := 1; offset <= len(s.songs); offset++ {
+		song := s.songs[(s.currentSong+offset)%len(s.songs)]
+		if !s.isUnplayable(song) {
+			return song
+		}
+	}
+	return nil
+}
+
+// fillerTitle returns what is announced while the station has nothing playable. songsMutex must be held
+func (s *Station) fillerTitle() string {
+	if s.fallback != nil {
+		return s.fallback.GetSongName()
+	}
+	return SilenceTitle
+}
+
+// playFiller sends a chunk of the fallback clip, or silence if there is none
+func (s *Station) playFiller() {
+	s.songsMutex.RLock()
+	fallback := s.fallback
+	s.songsMutex.RUnlock()
+	data := &SongData{
+		Data:       make([]byte, utils.SONGCHUNK),
+		LengthData: utils.SONGCHUNK,
+	}
+	if fallback != nil {
+		chunk, err := fallback.GetSongDataChunk()
+		if err != nil {
+			// the clip loops
+			fallback.ResetSong()
+			chunk, err = fallback.GetSongDataChunk()
+		}
+		if err == nil {
+			data = chunk
+		}
+	}
+	time.Sleep(s.sleepTime.Load())
+	s.publishData(data)
+}
+
+// watchHealth retries songs that couldn't be played until the station quits
+func (s *Station) watchHealth() {
+	ticker := time.NewTicker(utils.RETRYTIME * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.doneChan:
+			return
+		case <-ticker.C:
+			s.retryUnplayable()
+		}
+	}
+}
+
+// retryUnplayable opens songs that couldn't be played again
+func (s *Station) retryUnplayable() {
+	s.songsMutex.Lock()
+	recovered := make([]string, 0)
+	for song := range s.unplayable {
+		if song.Reopen() == nil && song.GetSize() > 0 {
+			delete(s.unplayable, song)
+			recovered = append(recovered, song.GetSongName())
+		}
+	}
+	s.songsMutex.Unlock()
+	for _, name := range recovered {
+		s.warn(fmt.Sprintf("station %s: %s can be played again", s.name, name))
+	}
+}
+
+// leaveSilence moves a silent station onto a song that can be played, if there is one now, and announces it
+func (s *Station) leaveSilence() {
+	s.songsMutex.Lock()
+	song := s.nextPlayable()
+	if !s.silent || song == nil {
+		s.songsMutex.Unlock()
+		return
+	}
+	s.setCurrent(song)
+	s.silent = false
+	s.songsMutex.Unlock()
+	s.publishChange(song.GetSongName())
+}
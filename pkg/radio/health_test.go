@@ -0,0 +1,99 @@
+package radio
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitForHealth polls the station until it reaches the health or the time runs out
+func waitForHealth(station *Station, expected string) (string, []string) {
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		health, unplayable := station.GetHealth()
+		if health == expected || time.Now().After(deadline) {
+			return health, unplayable
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSkipEmptySong(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snowcast")
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	empty := filepath.Join(dir, "empty.mp3")
+	ioutil.WriteFile(empty, []byte{}, 0644)
+	song := "../../mp3/mediumfile"
+	station, err := CreateStation([]string{empty, song})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	go station.StartStation()
+	defer station.Quit()
+	health, unplayable := waitForHealth(station, HealthDegraded)
+	if health != HealthDegraded {
+		t.Errorf("expected: %s, received: %s", HealthDegraded, health)
+	}
+	if len(unplayable) != 1 || unplayable[0] != empty {
+		t.Errorf("expected: [%s], received: %v", empty, unplayable)
+	}
+	if current := station.GetCurrentSong(); current != song {
+		t.Errorf("expected: %s, received: %s", song, current)
+	}
+}
+
+func TestSilentStation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snowcast")
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	empty := filepath.Join(dir, "empty.mp3")
+	empty2 := filepath.Join(dir, "empty2.mp3")
+	ioutil.WriteFile(empty, []byte{}, 0644)
+	ioutil.WriteFile(empty2, []byte{}, 0644)
+	station, err := CreateStation([]string{empty, empty2})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	go station.StartStation()
+	defer station.Quit()
+	health, unplayable := waitForHealth(station, HealthSilent)
+	if health != HealthSilent {
+		t.Errorf("expected: %s, received: %s", HealthSilent, health)
+	}
+	if len(unplayable) != 2 {
+		t.Errorf("expected: 2, received: %d", len(unplayable))
+	}
+	if current := station.GetCurrentSong(); current != SilenceTitle {
+		t.Errorf("expected: %s, received: %s", SilenceTitle, current)
+	}
+
+	// a song that is fixed plays again
+	ioutil.WriteFile(empty2, []byte("not empty"), 0644)
+	station.retryUnplayable()
+	health, _ = waitForHealth(station, HealthDegraded)
+	if health != HealthDegraded {
+		t.Errorf("expected: %s, received: %s", HealthDegraded, health)
+	}
+}
+
+func TestIdleStation(t *testing.T) {
+	station, err := CreateStation([]string{})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer station.quitStation()
+	health, unplayable := station.GetHealth()
+	if health != HealthIdle {
+		t.Errorf("expected: %s, received: %s", HealthIdle, health)
+	}
+	if len(unplayable) != 0 {
+		t.Errorf("expected: 0, received: %d", len(unplayable))
+	}
+}
@@ -0,0 +1,71 @@
+package radio
+
+import (
+	"math/rand"
+	"reflect"
+	"time"
+
+	"github.com/IMaloney/snowcast/pkg/config"
+)
+
+// SetInterstitials sets the clips the station plays between songs and how often it plays them
+func (s *Station) SetInterstitials(cfg config.InterstitialConfig) error {
+	s.songsMutex.RLock()
+	names := make([]string, 0)
+	for _, clip := range s.clips {
+		names = append(names, clip.GetSongName())
+	}
+	s.songsMutex.RUnlock()
+	clips := make([]*Song, 0)
+	if !reflect.DeepEqual(names, append(make([]string, 0), cfg.Clips...)) {
+		for _, name := range cfg.Clips {
+			clip, err := CreateSong(name)
+			if err != nil {
+				for _, clip := range clips {
+					clip.EndSong()
+				}
+				return err
+			}
+			clips = append(clips, clip)
+		}
+	}
+	s.songsMutex.Lock()
+	ended := make([]*Song, 0)
+	if len(clips) > 0 || len(cfg.Clips) == 0 {
+		ended = s.clips
+		s.clips = clips
+	}
+	s.everySongs = cfg.EverySongs
+	s.everyInterval = time.Duration(cfg.EveryMinutes) * time.Minute
+	s.songsMutex.Unlock()
+	// closing a clip that is playing ends it
+	for _, clip := range ended {
+		clip.EndSong()
+	}
+	return nil
+}
+
+// GetInterstitial returns the name of the clip playing, empty if a song is playing
+func (s *Station) GetInterstitial() string {
+	s.songsMutex.RLock()
+	defer s.songsMutex.RUnlock()
+	if s.clip == nil {
+		return ""
+	}
+	return s.clip.GetSongName()
+}
+
+// dueInterstitial returns a clip to play before the next song if one is due, nil otherwise. songsMutex must be held
+func (s *Station) dueInterstitial() *Song {
+	if len(s.clips) == 0 {
+		return nil
+	}
+	bySongs := s.everySongs > 0 && s.songsSinceClip >= s.everySongs
+	byTime := s.everyInterval > 0 && time.Since(s.lastClip) >= s.everyInterval
+	if !bySongs && !byTime {
+		return nil
+	}
+	s.songsSinceClip = 0
+	s.lastClip = time.Now()
+	return s.clips[rand.Intn(len(s.clips))]
+}
@@ -0,0 +1,58 @@
+package radio
+
+import (
+	"net"
+	"testing"
+
+	"github.com/IMaloney/snowcast/pkg/config"
+)
+
+func TestInterstitials(t *testing.T) {
+	udpAddr, err := net.ResolveUDPAddr("udp", ":4446")
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	udpConn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	defer udpConn.Close()
+	subscriber := CreateSubscriber(udpConn)
+
+	song := "../../mp3/tinyfile"
+	song2 := "../../mp3/mediumfile"
+	clip := "../../mp3/FX-Impact193.mp3"
+	station, err := CreateStationFromConfig(config.StationConfig{
+		Name:          "ids",
+		Songs:         []string{song, song2},
+		Interstitials: config.InterstitialConfig{Clips: []string{clip}, EverySongs: 2},
+	})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer station.quitStation()
+	station.subscribe(udpConn.RemoteAddr(), subscriber)
+	// clips aren't songs of the station
+	if songs := station.GetStationSongs(); len(songs) != 2 {
+		t.Errorf("expected: 2, received: %d", len(songs))
+	}
+	station.nextSong(station.songs[station.currentSong])
+	if name := <-subscriber.ChangeSong; name != song2 {
+		t.Errorf("expected: %s, received: %s", song2, name)
+	}
+	station.nextSong(station.songs[station.currentSong])
+	if name := <-subscriber.PlayInterstitial; name != clip {
+		t.Errorf("expected: %s, received: %s", clip, name)
+	}
+	if station.GetInterstitial() != clip {
+		t.Errorf("expected: %s, received: %s", clip, station.GetInterstitial())
+	}
+	// the song after the clip is announced once the clip ends
+	station.nextSong(station.clip)
+	if name := <-subscriber.ChangeSong; name != song {
+		t.Errorf("expected: %s, received: %s", song, name)
+	}
+	if station.GetInterstitial() != "" {
+		t.Errorf("expected: nothing, received: %s", station.GetInterstitial())
+	}
+}
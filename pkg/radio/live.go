@@ -0,0 +1,103 @@
+package radio
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/IMaloney/snowcast/pkg/utils"
+)
+
+type LiveSource struct {
+	title    string
+	reader   io.ReadCloser
+	dataChan chan *SongData
+	quitChan chan struct{}
+	quitOnce sync.Once
+}
+
+// CreateLiveSource opens a live feed. The feed is either stdin, fifo:[path] or udp:[port]
+func CreateLiveSource(feed, title string) (*LiveSource, error) {
+	kind, arg := feed, ""
+	if idx := strings.Index(feed, ":"); idx >= 0 {
+		kind, arg = feed[:idx], feed[idx+1:]
+	}
+	var reader io.ReadCloser
+	switch kind {
+	case "stdin":
+		reader = os.Stdin
+	case "fifo":
+		// opening read-write keeps the pipe open between encoder runs so the feed doesn't hit EOF each time a writer leaves
+		file, err := os.OpenFile(arg, os.O_RDWR, 0)
+		if err != nil {
+			return nil, fmt.Errorf("Could not open named pipe %s. Error: %v", arg, err)
+		}
+		reader = file
+	case "udp":
+		udpAddr, err := net.ResolveUDPAddr("udp", ":"+arg)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve udp address from port %s. Error: %v", arg, err)
+		}
+		udpConn, err := net.ListenUDP("udp", udpAddr)
+		if err != nil {
+			return nil, fmt.Errorf("could not listen for live feed on port %s. Error: %v", arg, err)
+		}
+		reader = udpConn
+	default:
+		return nil, fmt.Errorf("live feed %s not recognized", feed)
+	}
+	return CreateStreamSource(reader, title), nil
+}
+
+// CreateStreamSource wraps a reader as a live feed and starts pulling data from it
+func CreateStreamSource(reader io.ReadCloser, title string) *LiveSource {
+	live := &LiveSource{
+		title:    title,
+		reader:   reader,
+		dataChan: make(chan *SongData, utils.LIVEBUFFER),
+		quitChan: make(chan struct{}),
+	}
+	go live.pump()
+	return live
+}
+
+// GetTitle returns the title announced while the feed is on air
+func (l *LiveSource) GetTitle() string {
+	return l.title
+}
+
+// pump reads from the feed and splits the data into song chunks. The data channel is closed when the feed ends
+func (l *LiveSource) pump() {
+	defer close(l.dataChan)
+	buffer := make([]byte, utils.BUFFSIZE)
+	for {
+		n, err := l.reader.Read(buffer)
+		for start := 0; start < n; start += utils.SONGCHUNK {
+			end := start + utils.SONGCHUNK
+			if end > n {
+				end = n
+			}
+			chunk := make([]byte, utils.SONGCHUNK)
+			copy(chunk, buffer[start:end])
+			select {
+			case l.dataChan <- &SongData{Data: chunk, LengthData: end - start}:
+			case <-l.quitChan:
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Quit closes the live feed
+func (l *LiveSource) Quit() {
+	l.quitOnce.Do(func() {
+		close(l.quitChan)
+		l.reader.Close()
+	})
+}
@@ -0,0 +1,110 @@
+package radio
+
+import (
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/IMaloney/snowcast/pkg/utils"
+)
+
+func TestCreateLiveSource(t *testing.T) {
+	_, err := CreateLiveSource("poop", "title")
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	_, err = CreateLiveSource("fifo:../../mp3/poop", "title")
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	live, err := CreateLiveSource("udp:6666", "title")
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer live.Quit()
+	if live.GetTitle() != "title" {
+		t.Errorf("expected: title, received: %s", live.GetTitle())
+	}
+	udpAddr, err := net.ResolveUDPAddr("udp", ":6666")
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	udpConn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	defer udpConn.Close()
+	udpConn.Write([]byte("hello"))
+	select {
+	case data := <-live.dataChan:
+		if string(data.Data[:data.LengthData]) != "hello" {
+			t.Errorf("expected: hello, received: %s", data.Data[:data.LengthData])
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("expected: data, received: nothing")
+	}
+}
+
+func TestLiveSourceChunks(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	live := CreateStreamSource(reader, "title")
+	defer live.Quit()
+	writer.Write(make([]byte, utils.SONGCHUNK+10))
+	writer.Close()
+	data := <-live.dataChan
+	if data.LengthData != utils.SONGCHUNK {
+		t.Errorf("expected: %d, received: %d", utils.SONGCHUNK, data.LengthData)
+	}
+	data = <-live.dataChan
+	if data.LengthData != 10 {
+		t.Errorf("expected: 10, received: %d", data.LengthData)
+	}
+	if _, ok := <-live.dataChan; ok {
+		t.Errorf("expected: closed channel, received: data")
+	}
+}
+
+func TestStartLiveStation(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	udpAddr, err := net.ResolveUDPAddr("udp", ":4444")
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	udpConn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	defer udpConn.Close()
+	subscriber := CreateSubscriber(udpConn)
+
+	song := "../../mp3/tinyfile"
+	station, err := CreateLiveStation(CreateStreamSource(reader, "Morning Show"), []string{song})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	station.subscribe(udpConn.RemoteAddr(), subscriber)
+	go station.StartStation()
+	defer station.Quit()
+	writer.Write([]byte("live audio"))
+	if name := <-subscriber.ChangeSong; name != "Morning Show" {
+		t.Errorf("expected: Morning Show, received: %s", name)
+	}
+	if !station.IsLive() {
+		t.Errorf("expected: true, received: false")
+	}
+	// ending the feed falls back to the playlist
+	writer.Close()
+	if name := <-subscriber.ChangeSong; name != song {
+		t.Errorf("expected: %s, received: %s", song, name)
+	}
+	if station.IsLive() {
+		t.Errorf("expected: false, received: true")
+	}
+}
@@ -0,0 +1,147 @@
+package radio
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/IMaloney/snowcast/pkg/config"
+)
+
+// ReadPlaylist returns the songs listed in an .m3u, .m3u8 or .pls playlist. Relative paths are relative to the playlist
+func ReadPlaylist(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Could not open playlist %s. Error: %v", path, err)
+	}
+	defer file.Close()
+	var entries []string
+	if strings.ToLower(filepath.Ext(path)) == ".pls" {
+		entries, err = parsePLS(file)
+	} else {
+		entries, err = parseM3U(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Could not read playlist %s. Error: %v", path, err)
+	}
+	songs := make([]string, 0)
+	for _, entry := range entries {
+		// streams can't be played as songs
+		if strings.Contains(entry, "://") {
+			continue
+		}
+		if !filepath.IsAbs(entry) {
+			entry = filepath.Join(filepath.Dir(path), entry)
+		}
+		songs = append(songs, entry)
+	}
+	return songs, nil
+}
+
+// parseM3U returns every line of an m3u playlist that isn't blank or a comment
+func parseM3U(file *os.File) ([]string, error) {
+	entries := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	return entries, scanner.Err()
+}
+
+// parsePLS returns the File[n] entries of a pls playlist in order of n
+func parsePLS(file *os.File) ([]string, error) {
+	files := make(map[int]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(strings.ToLower(line), "file") {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		num, err := strconv.Atoi(parts[0][len("file"):])
+		if err != nil {
+			continue
+		}
+		files[num] = strings.TrimSpace(parts[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	nums := make([]int, 0)
+	for num := range files {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	entries := make([]string, 0)
+	for _, num := range nums {
+		entries = append(entries, files[num])
+	}
+	return entries, nil
+}
+
+// ScanDirectory returns the files in a directory in name order, skipping hidden files and playlists
+func ScanDirectory(dir string) ([]string, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("Could not scan directory %s. Error: %v", dir, err)
+	}
+	songs := make([]string, 0)
+	for _, info := range infos {
+		if !info.Mode().IsRegular() || strings.HasPrefix(info.Name(), ".") || config.IsPlaylist(info.Name()) {
+			continue
+		}
+		songs = append(songs, filepath.Join(dir, info.Name()))
+	}
+	return songs, nil
+}
+
+// expandSongs returns the listed songs of a station config followed by its playlist, directory and category songs
+func expandSongs(cfg config.StationConfig) ([]string, error) {
+	songs := make([]string, 0)
+	songs = append(songs, cfg.Songs...)
+	if cfg.Playlist != "" {
+		playlistSongs, err := ReadPlaylist(cfg.Playlist)
+		if err != nil {
+			return nil, err
+		}
+		songs = append(songs, playlistSongs...)
+	}
+	if cfg.Directory != "" {
+		directorySongs, err := ScanDirectory(cfg.Directory)
+		if err != nil {
+			return nil, err
+		}
+		songs = append(songs, directorySongs...)
+	}
+	listed := make(map[string]bool)
+	for _, song := range songs {
+		listed[song] = true
+	}
+	categories := make([]string, 0)
+	for category := range cfg.Categories {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		for _, categorySong := range cfg.Categories[category] {
+			// a song can be in more than one category but is only on the station once
+			if !listed[categorySong.Song] {
+				listed[categorySong.Song] = true
+				songs = append(songs, categorySong.Song)
+			}
+		}
+	}
+	return songs, nil
+}
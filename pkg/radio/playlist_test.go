@@ -0,0 +1,92 @@
+package radio
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/IMaloney/snowcast/pkg/config"
+)
+
+func TestReadPlaylist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snowcast")
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	_, err = ReadPlaylist(filepath.Join(dir, "poop.m3u"))
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	m3u := filepath.Join(dir, "list.m3u")
+	ioutil.WriteFile(m3u, []byte("#EXTM3U\n#EXTINF:123,Artist - Song\na.mp3\n\n/music/b.mp3\nhttp://stream.example.com/live\n"), 0644)
+	songs, err := ReadPlaylist(m3u)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	if len(songs) != 2 {
+		t.Fatalf("expected: 2, received: %d", len(songs))
+	}
+	if songs[0] != filepath.Join(dir, "a.mp3") {
+		t.Errorf("expected: %s, received: %s", filepath.Join(dir, "a.mp3"), songs[0])
+	}
+	if songs[1] != "/music/b.mp3" {
+		t.Errorf("expected: /music/b.mp3, received: %s", songs[1])
+	}
+	pls := filepath.Join(dir, "list.pls")
+	ioutil.WriteFile(pls, []byte("[playlist]\nFile2=b.mp3\nTitle2=B\nFile1=a.mp3\nNumberOfEntries=2\nVersion=2\n"), 0644)
+	songs, err = ReadPlaylist(pls)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	if len(songs) != 2 {
+		t.Fatalf("expected: 2, received: %d", len(songs))
+	}
+	if songs[0] != filepath.Join(dir, "a.mp3") || songs[1] != filepath.Join(dir, "b.mp3") {
+		t.Errorf("expected: a.mp3 then b.mp3, received: %v", songs)
+	}
+}
+
+func TestScanDirectory(t *testing.T) {
+	_, err := ScanDirectory("../../poop")
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	songs, err := ScanDirectory("../../mp3")
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	if len(songs) == 0 {
+		t.Errorf("expected: songs, received: none")
+	}
+	for _, song := range songs {
+		if filepath.Dir(song) != "../../mp3" {
+			t.Errorf("expected: ../../mp3, received: %s", filepath.Dir(song))
+		}
+	}
+}
+
+func TestExpandSongs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snowcast")
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	m3u := filepath.Join(dir, "list.m3u")
+	ioutil.WriteFile(m3u, []byte("a.mp3\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "b.mp3"), []byte("b"), 0644)
+	songs, err := expandSongs(config.StationConfig{Songs: []string{"c.mp3"}, Playlist: m3u, Directory: dir})
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	expected := []string{"c.mp3", filepath.Join(dir, "a.mp3"), filepath.Join(dir, "b.mp3")}
+	if len(songs) != len(expected) {
+		t.Fatalf("expected: %v, received: %v", expected, songs)
+	}
+	for idx := range expected {
+		if songs[idx] != expected[idx] {
+			t.Errorf("expected: %s, received: %s", expected[idx], songs[idx])
+		}
+	}
+}
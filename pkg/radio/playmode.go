@@ -0,0 +1,264 @@
+package radio
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/IMaloney/snowcast/pkg/utils"
+)
+
+type PlayMode uint8
+
+const (
+	// plays the songs in order forever
+	Sequential PlayMode = iota
+	// plays every song once per cycle in an order reshuffled each cycle
+	Shuffle
+	// picks songs at random, never repeating one of the last noRepeat songs
+	Random
+	// plays the songs in order once then stops
+	Once
+	// plays a song from each category of the station clock in turn, picked by weight
+	Clock
+)
+
+var playModeNames = map[PlayMode]string{
+	Sequential: "sequential",
+	Shuffle:    "shuffle",
+	Random:     "random",
+	Once:       "once",
+	Clock:      "clock",
+}
+
+// ParsePlayMode returns the play mode with the given name. An empty name is sequential
+func ParsePlayMode(name string) (PlayMode, error) {
+	if name == "" {
+		return Sequential, nil
+	}
+	for mode, modeName := range playModeNames {
+		if modeName == name {
+			return mode, nil
+		}
+	}
+	return Sequential, fmt.Errorf("play mode %s not recognized. Use sequential, shuffle, random, once or clock", name)
+}
+
+func (m PlayMode) String() string {
+	return playModeNames[m]
+}
+
+// SetPlayMode changes how the station picks its next songs. The upcoming songs are planned again from the current song
+func (s *Station) SetPlayMode(mode PlayMode, noRepeat int) {
+	s.songsMutex.Lock()
+	s.mode = mode
+	s.noRepeat = noRepeat
+	s.upcoming = make([]*Song, 0)
+	stopped := s.stopped
+	s.stopped = false
+	var songName string
+	if stopped && len(s.songs) > 0 {
+		// a station that finished playing once picks up again with the next song, or from the top
+		if next := s.popUpcoming(); next != nil {
+			s.setCurrent(next)
+		} else {
+			s.currentSong = 0
+		}
+		songName = s.songs[s.currentSong].GetSongName()
+	}
+	s.songsMutex.Unlock()
+	if songName != "" {
+		s.publishChange(songName)
+	}
+}
+
+// GetPlayMode returns the play mode of the station and its no repeat window
+func (s *Station) GetPlayMode() (PlayMode, int) {
+	s.songsMutex.RLock()
+	defer s.songsMutex.RUnlock()
+	return s.mode, s.noRepeat
+}
+
+// GetUpcomingSongs returns the next num songs the station will play, in order, and at most PLAYLISTLIMIT of them.
+// The station plans far enough ahead to answer, so the songs come up in the order they are returned
+func (s *Station) GetUpcomingSongs(num int) []string {
+	if num > utils.PLAYLISTLIMIT {
+		num = utils.PLAYLISTLIMIT
+	}
+	s.songsMutex.Lock()
+	defer s.songsMutex.Unlock()
+	for len(s.upcoming) < num {
+		if !s.planUpcoming() {
+			break
+		}
+	}
+	songs := make([]string, 0)
+	// requests play before the planned songs
+	for _, request := range s.requests {
+		if len(songs) < num {
+			songs = append(songs, request.song.GetSongName())
+		}
+	}
+	for idx := 0; len(songs) < num && idx < len(s.upcoming); idx++ {
+		songs = append(songs, s.upcoming[idx].GetSongName())
+	}
+	return songs
+}
+
+// popUpcoming takes the next song off the upcoming songs. Returns nil if there are no more songs to play.
+// songsMutex must be held
+func (s *Station) popUpcoming() *Song {
+	if len(s.upcoming) == 0 && !s.planUpcoming() {
+		return nil
+	}
+	next := s.upcoming[0]
+	s.upcoming = s.upcoming[1:]
+	if len(s.history) == 0 {
+		// the first song started without being popped so it has to be remembered as it finishes
+		s.remember(s.songs[s.currentSong])
+	}
+	s.remember(next)
+	return next
+}
+
+// remember adds a song to the songs played lately. songsMutex must be held
+func (s *Station) remember(song *Song) {
+	s.history = append(s.history, song)
+	if len(s.history) > len(s.songs) {
+		s.history = s.history[len(s.history)-len(s.songs):]
+	}
+}
+
+// planUpcoming adds songs to the upcoming songs according to the play mode. Returns false if nothing was added.
+// songsMutex must be held
+func (s *Station) planUpcoming() bool {
+	if len(s.songs) == 0 {
+		return false
+	}
+	// planning carries on from the last planned song, or the current song if nothing is planned
+	last := s.currentSong
+	if len(s.upcoming) > 0 {
+		last = s.songIndex(s.upcoming[len(s.upcoming)-1])
+	}
+	planned := len(s.upcoming)
+	switch s.mode {
+	case Sequential:
+		for offset := 1; offset <= len(s.songs); offset++ {
+			s.upcoming = append(s.upcoming, s.songs[(last+offset)%len(s.songs)])
+		}
+	case Once:
+		for idx := last + 1; idx < len(s.songs); idx++ {
+			s.upcoming = append(s.upcoming, s.songs[idx])
+		}
+	case Shuffle:
+		order := rand.Perm(len(s.songs))
+		// avoid playing the same song twice in a row across cycles
+		if len(order) > 1 && order[0] == last {
+			order[0], order[1] = order[1], order[0]
+		}
+		for _, idx := range order {
+			s.upcoming = append(s.upcoming, s.songs[idx])
+		}
+	case Random:
+		s.upcoming = append(s.upcoming, s.pickRandom(s.songs))
+	case Clock:
+		s.planClock(last)
+	}
+	return len(s.upcoming) > planned
+}
+
+// pickRandom picks a song from songs by weight, skipping any of the last noRepeat songs played or planned.
+// songsMutex must be held
+func (s *Station) pickRandom(songs []*Song) *Song {
+	window := s.noRepeat
+	if window > len(s.songs)-1 {
+		window = len(s.songs) - 1
+	}
+	recent := append(append(make([]*Song, 0), s.history...), s.upcoming...)
+	if len(s.history) == 0 {
+		// nothing has finished yet so the first song is still playing
+		recent = append([]*Song{s.songs[s.currentSong]}, recent...)
+	}
+	if len(recent) > window {
+		recent = recent[len(recent)-window:]
+	}
+	blocked := make(map[*Song]bool)
+	for _, song := range recent {
+		blocked[song] = true
+	}
+	choices := make([]*Song, 0)
+	for _, song := range songs {
+		if !blocked[song] {
+			choices = append(choices, song)
+		}
+	}
+	if len(choices) == 0 {
+		choices = songs
+	}
+	total := 0
+	for _, song := range choices {
+		total += s.songWeight(song)
+	}
+	pick := rand.Intn(total)
+	for _, song := range choices {
+		pick -= s.songWeight(song)
+		if pick < 0 {
+			return song
+		}
+	}
+	return choices[len(choices)-1]
+}
+
+// songWeight returns how likely a song is to be picked at random. songsMutex must be held
+func (s *Station) songWeight(song *Song) int {
+	if weight := s.weights[song.GetSongName()]; weight > 0 {
+		return weight
+	}
+	return 1
+}
+
+// songIndex returns the index of a song on the station, 0 if it is not on the station. songsMutex must be held
+func (s *Station) songIndex(song *Song) int {
+	for idx, stationSong := range s.songs {
+		if stationSong == song {
+			return idx
+		}
+	}
+	return 0
+}
+
+// setCurrent makes a song the current song. songsMutex must be held
+func (s *Station) setCurrent(song *Song) {
+	s.currentSong = s.songIndex(song)
+}
+
+// forgetSongs drops songs that are no longer on the station from the upcoming songs, history, unplayable songs and requests.
+// Ordered play modes plan again from scratch since the order has changed. songsMutex must be held
+func (s *Station) forgetSongs() {
+	if s.mode == Sequential || s.mode == Once {
+		s.upcoming = make([]*Song, 0)
+	}
+	onStation := make(map[*Song]bool)
+	for _, song := range s.songs {
+		onStation[song] = true
+	}
+	upcoming := make([]*Song, 0)
+	for _, song := range s.upcoming {
+		if onStation[song] {
+			upcoming = append(upcoming, song)
+		}
+	}
+	s.upcoming = upcoming
+	history := make([]*Song, 0)
+	for _, song := range s.history {
+		if onStation[song] {
+			history = append(history, song)
+		}
+	}
+	s.history = history
+	for song := range s.unplayable {
+		if !onStation[song] {
+			delete(s.unplayable, song)
+		}
+	}
+	s.forgetRequests(onStation)
+}
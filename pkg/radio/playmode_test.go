@@ -0,0 +1,146 @@
+package radio
+
+import (
+	"testing"
+
+	"github.com/IMaloney/snowcast/pkg/utils"
+)
+
+func TestParsePlayMode(t *testing.T) {
+	if _, err := ParsePlayMode("poop"); err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	mode, err := ParsePlayMode("")
+	if err != nil || mode != Sequential {
+		t.Errorf("expected: sequential, received: %s %v", mode, err)
+	}
+	for _, name := range []string{"sequential", "shuffle", "random", "once"} {
+		mode, err := ParsePlayMode(name)
+		if err != nil {
+			t.Errorf("expected: nil, received: %v", err)
+		}
+		if mode.String() != name {
+			t.Errorf("expected: %s, received: %s", name, mode.String())
+		}
+	}
+}
+
+func TestSequentialUpcoming(t *testing.T) {
+	song := "../../mp3/tinyfile"
+	song2 := "../../mp3/mediumfile"
+	song3 := "../../mp3/FX-Impact193.mp3"
+	station, err := CreateStation([]string{song, song2, song3})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer station.quitStation()
+	upcoming := station.GetUpcomingSongs(4)
+	expected := []string{song2, song3, song, song2}
+	for idx := range expected {
+		if upcoming[idx] != expected[idx] {
+			t.Errorf("expected: %s, received: %s", expected[idx], upcoming[idx])
+		}
+	}
+	if upcoming := station.GetUpcomingSongs(utils.PLAYLISTLIMIT + 50); len(upcoming) != utils.PLAYLISTLIMIT {
+		t.Errorf("expected: %d, received: %d", utils.PLAYLISTLIMIT, len(upcoming))
+	}
+	// what is played matches what was planned
+	for _, name := range expected {
+		station.nextSong(station.songs[station.currentSong])
+		if station.GetCurrentSong() != name {
+			t.Errorf("expected: %s, received: %s", name, station.GetCurrentSong())
+		}
+	}
+}
+
+func TestShuffleUpcoming(t *testing.T) {
+	song := "../../mp3/tinyfile"
+	song2 := "../../mp3/mediumfile"
+	song3 := "../../mp3/FX-Impact193.mp3"
+	station, err := CreateStation([]string{song, song2, song3})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer station.quitStation()
+	station.SetPlayMode(Shuffle, 0)
+	upcoming := station.GetUpcomingSongs(6)
+	// looking again doesn't change the plan
+	for idx, name := range station.GetUpcomingSongs(9)[:6] {
+		if name != upcoming[idx] {
+			t.Errorf("expected: %s, received: %s", upcoming[idx], name)
+		}
+	}
+	// every cycle plays every song once
+	for cycle := 0; cycle < 2; cycle++ {
+		seen := make(map[string]bool)
+		for _, name := range upcoming[cycle*3 : cycle*3+3] {
+			seen[name] = true
+		}
+		if len(seen) != 3 {
+			t.Errorf("expected: 3, received: %d", len(seen))
+		}
+	}
+	for _, name := range upcoming {
+		station.nextSong(station.songs[station.currentSong])
+		if station.GetCurrentSong() != name {
+			t.Errorf("expected: %s, received: %s", name, station.GetCurrentSong())
+		}
+	}
+}
+
+func TestRandomNoRepeat(t *testing.T) {
+	song := "../../mp3/tinyfile"
+	song2 := "../../mp3/mediumfile"
+	song3 := "../../mp3/FX-Impact193.mp3"
+	station, err := CreateStation([]string{song, song2, song3})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer station.quitStation()
+	station.SetPlayMode(Random, 2)
+	upcoming := append([]string{station.GetCurrentSong()}, station.GetUpcomingSongs(30)...)
+	for idx := 1; idx < len(upcoming); idx++ {
+		if upcoming[idx] == upcoming[idx-1] {
+			t.Errorf("expected: no repeat, received: %s twice in a row", upcoming[idx])
+		}
+		if idx > 1 && upcoming[idx] == upcoming[idx-2] {
+			t.Errorf("expected: no repeat within 2, received: %s", upcoming[idx])
+		}
+	}
+	for _, name := range upcoming[1:] {
+		station.nextSong(station.songs[station.currentSong])
+		if station.GetCurrentSong() != name {
+			t.Errorf("expected: %s, received: %s", name, station.GetCurrentSong())
+		}
+	}
+}
+
+func TestPlayOnce(t *testing.T) {
+	song := "../../mp3/tinyfile"
+	song2 := "../../mp3/mediumfile"
+	station, err := CreateStation([]string{song, song2})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer station.quitStation()
+	station.SetPlayMode(Once, 0)
+	if upcoming := station.GetUpcomingSongs(5); len(upcoming) != 1 || upcoming[0] != song2 {
+		t.Errorf("expected: [%s], received: %v", song2, upcoming)
+	}
+	station.nextSong(station.songs[station.currentSong])
+	station.nextSong(station.songs[station.currentSong])
+	if !station.stopped {
+		t.Errorf("expected: true, received: false")
+	}
+	if station.GetCurrentSong() != "" {
+		t.Errorf("expected: nothing playing, received: %s", station.GetCurrentSong())
+	}
+	// switching modes starts the station again
+	station.SetPlayMode(Sequential, 0)
+	if station.stopped {
+		t.Errorf("expected: false, received: true")
+	}
+	if station.GetCurrentSong() != song {
+		t.Errorf("expected: %s, received: %s", song, station.GetCurrentSong())
+	}
+}
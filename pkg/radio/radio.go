@@ -3,45 +3,91 @@ package radio
 import (
 	"fmt"
 	"net"
-	"strings"
+	"sort"
 	"sync"
+	"time"
 
+	"github.com/IMaloney/snowcast/pkg/config"
+	"github.com/IMaloney/snowcast/pkg/utils"
 	"go.uber.org/atomic"
 )
 
+// StationInfo describes a station to clients choosing one
+type StationInfo struct {
+	Number      uint16
+	Name        string
+	Description string
+	Song        string
+	Listeners   int
+}
+
+// StationSongs holds the songs of a station and the song playing on it
+type StationSongs struct {
+	Number     uint16
+	Name       string
+	NowPlaying string
+	Songs      []string
+}
+
 type Radio struct {
 	numStations     *atomic.Uint32
 	stationsIdx     *atomic.Uint32
 	stationMap      map[uint16]*Station
 	stationMapMutex sync.RWMutex
+	warnChan        chan string
 }
 
-// CreateRadio creates a radio which plays stations simultaneously
-func CreateRadio(files []string, extraCredit bool) (*Radio, error) {
-	numStations := atomic.NewUint32(uint32(len(files)))
+// CreateRadio creates a radio which plays the configured stations simultaneously
+func CreateRadio(cfg *config.Config) (*Radio, error) {
+	numStations := atomic.NewUint32(uint32(len(cfg.Stations)))
 	radioMap := make(map[uint16]*Station)
-	stationsIdx := atomic.NewUint32(0)
-	for idx, name := range files {
-		var station *Station
-		var err error
-		idx := uint16(idx)
-		if extraCredit {
-			songs := strings.Split(name, ",")
-			station, err = CreateStation(songs)
-		} else {
-			station, err = CreateStation([]string{name})
+	warnChan := make(chan string, utils.WARNBUFFER)
+	// stations with an id keep it and the others are numbered in order around them
+	stationNums := make([]uint16, len(cfg.Stations))
+	taken := make(map[uint16]bool)
+	for idx, stationConfig := range cfg.Stations {
+		if stationConfig.ID != nil {
+			stationNums[idx] = uint16(*stationConfig.ID)
+			taken[stationNums[idx]] = true
+		}
+	}
+	next := uint16(0)
+	for idx, stationConfig := range cfg.Stations {
+		if stationConfig.ID != nil {
+			continue
 		}
+		for taken[next] {
+			next++
+		}
+		stationNums[idx] = next
+		next++
+	}
+	stationsIdx := atomic.NewUint32(uint32(next))
+	for idx, stationConfig := range cfg.Stations {
+		stationNum := stationNums[idx]
+		station, err := CreateStationFromConfig(stationConfig)
 		if err != nil {
-			return nil, fmt.Errorf("Could not create Radio. %d. Error: %v", idx, err)
+			for _, created := range radioMap {
+				created.quitStation()
+			}
+			return nil, fmt.Errorf("Could not create Radio. %d. Error: %v", stationNum, err)
 		}
-		radioMap[idx] = station
-		stationsIdx.Inc()
+		station.warnChan = warnChan
+		radioMap[stationNum] = station
 	}
 
 	radio := &Radio{
 		numStations: numStations,
 		stationMap:  radioMap,
 		stationsIdx: stationsIdx,
+		warnChan:    warnChan,
+	}
+	if cfg.StateFile != "" {
+		err := radio.RestoreState(cfg.StateFile)
+		if err != nil {
+			radio.Quit()
+			return nil, err
+		}
 	}
 
 	for _, station := range radioMap {
@@ -53,6 +99,36 @@ func CreateRadio(files []string, extraCredit bool) (*Radio, error) {
 	return radio, nil
 }
 
+// Warnings returns the warnings of every station, e.g. songs that can't be played
+func (r *Radio) Warnings() <-chan string {
+	return r.warnChan
+}
+
+// GetHealth gets the health of a station and the songs it can't play
+func (r *Radio) GetHealth(stationNum uint16) (string, []string, error) {
+	if !r.stationExists(stationNum) {
+		return "", nil, fmt.Errorf("Station %d does not exist", stationNum)
+	}
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	health, unplayable := r.stationMap[stationNum].GetHealth()
+	return health, unplayable, nil
+}
+
+// GetStationNumbers returns the numbers of every station in order
+func (r *Radio) GetStationNumbers() []uint16 {
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	stationNums := make([]uint16, 0)
+	for stationNum := range r.stationMap {
+		stationNums = append(stationNums, stationNum)
+	}
+	sort.Slice(stationNums, func(i, j int) bool {
+		return stationNums[i] < stationNums[j]
+	})
+	return stationNums
+}
+
 // GetNumStations gets the number of stations playing on the radio
 func (r *Radio) GetNumStations() uint16 {
 	return uint16(r.numStations.Load())
@@ -65,8 +141,7 @@ func (r *Radio) JoinStation(stationNum uint16, conn net.Addr, subscriber *Subscr
 	}
 	r.stationMapMutex.RLock()
 	defer r.stationMapMutex.RUnlock()
-	r.stationMap[stationNum].subscribe(conn, subscriber)
-	return nil
+	return r.stationMap[stationNum].subscribe(conn, subscriber)
 }
 
 // LeaveStation lets a client leave a station. Error is returned if the station didn't exist or the client never subscribed
@@ -93,6 +168,77 @@ func (r *Radio) GetSongName(station uint16) (string, error) {
 	return r.stationMap[station].GetCurrentSong(), nil
 }
 
+// AttachLiveSource puts a live source on a station. The station falls back to its playlist when the source ends
+func (r *Radio) AttachLiveSource(stationNum uint16, live *LiveSource) error {
+	if !r.stationExists(stationNum) {
+		return fmt.Errorf("Station %d does not exist", stationNum)
+	}
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	return r.stationMap[stationNum].AttachLive(live)
+}
+
+// GetStationByName returns the number of the station with the given name
+func (r *Radio) GetStationByName(name string) (uint16, bool) {
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	for stationNum, station := range r.stationMap {
+		if station.GetName() == name {
+			return stationNum, true
+		}
+	}
+	return 0, false
+}
+
+// GetStationNames returns a map of station name to station number
+func (r *Radio) GetStationNames() map[string]uint16 {
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	names := make(map[string]uint16)
+	for stationNum, station := range r.stationMap {
+		names[station.GetName()] = stationNum
+	}
+	return names
+}
+
+// GetLiveFeed returns the configured live feed of a station
+func (r *Radio) GetLiveFeed(stationNum uint16) (string, error) {
+	if !r.stationExists(stationNum) {
+		return "", fmt.Errorf("Station %d does not exist", stationNum)
+	}
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	return r.stationMap[stationNum].GetLiveFeed(), nil
+}
+
+// UpdateStation updates the songs and settings of a running station in place
+func (r *Radio) UpdateStation(stationNum uint16, stationConfig config.StationConfig) error {
+	if !r.stationExists(stationNum) {
+		return fmt.Errorf("Station %d does not exist", stationNum)
+	}
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	station := r.stationMap[stationNum]
+	// during a show the station songs belong to the show. The regular songs are loaded again when it ends
+	if station.GetShow() == "" {
+		songs, err := expandSongs(stationConfig)
+		if err != nil {
+			return err
+		}
+		err = station.SetSongs(songs)
+		if err != nil {
+			return err
+		}
+	}
+	err := station.ApplySettings(stationConfig)
+	if err != nil {
+		return err
+	}
+	// a reload can give the station a directory to watch
+	station.startDirectoryWatch()
+	return station.checkSchedule(time.Now())
+}
+
 // stationExists returns true if the station exists and false if not
 func (r *Radio) stationExists(station uint16) bool {
 	r.stationMapMutex.RLock()
@@ -114,22 +260,266 @@ func (r *Radio) GetStationSongs(stationNum uint16) ([]string, error) {
 	return songs, nil
 }
 
-// AddStation adds a station to the radio
+// SetPlayMode changes the play mode of a station
+func (r *Radio) SetPlayMode(stationNum uint16, mode PlayMode, noRepeat int) error {
+	if !r.stationExists(stationNum) {
+		return fmt.Errorf("Station %d does not exist", stationNum)
+	}
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	r.stationMap[stationNum].SetPlayMode(mode, noRepeat)
+	return nil
+}
+
+// GetUpcomingSongs gets the next num songs a station will play
+func (r *Radio) GetUpcomingSongs(stationNum uint16, num int) ([]string, error) {
+	if !r.stationExists(stationNum) {
+		return []string{}, fmt.Errorf("Station %d does not exist", stationNum)
+	}
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	return r.stationMap[stationNum].GetUpcomingSongs(num), nil
+}
+
+// GetShows gets the show on a station now, the next show to start and when it starts
+func (r *Radio) GetShows(stationNum uint16) (string, string, time.Time, error) {
+	if !r.stationExists(stationNum) {
+		return "", "", time.Time{}, fmt.Errorf("Station %d does not exist", stationNum)
+	}
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	current, next, start := r.stationMap[stationNum].GetShows(time.Now())
+	return current, next, start, nil
+}
+
+// AddSong adds a song to the end of a station
+func (r *Radio) AddSong(stationNum uint16, name string) error {
+	if !r.stationExists(stationNum) {
+		return fmt.Errorf("Station %d does not exist", stationNum)
+	}
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	return r.stationMap[stationNum].AddSong(name)
+}
+
+// RemoveSong removes a song from a station
+func (r *Radio) RemoveSong(stationNum uint16, name string) error {
+	if !r.stationExists(stationNum) {
+		return fmt.Errorf("Station %d does not exist", stationNum)
+	}
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	return r.stationMap[stationNum].RemoveSong(name)
+}
+
+// MoveSong moves a song on a station from one position to another
+func (r *Radio) MoveSong(stationNum uint16, from, to int) error {
+	if !r.stationExists(stationNum) {
+		return fmt.Errorf("Station %d does not exist", stationNum)
+	}
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	return r.stationMap[stationNum].MoveSong(from, to)
+}
+
+// SkipSong skips the song playing on a station
+func (r *Radio) SkipSong(stationNum uint16) error {
+	if !r.stationExists(stationNum) {
+		return fmt.Errorf("Station %d does not exist", stationNum)
+	}
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	return r.stationMap[stationNum].Skip()
+}
+
+// RequestSong queues a song of a station to play next. onStart is called when the song starts.
+// Returns the place of the song in the queue of requests
+func (r *Radio) RequestSong(stationNum uint16, name string, onStart func()) (int, error) {
+	if !r.stationExists(stationNum) {
+		return 0, fmt.Errorf("Station %d does not exist", stationNum)
+	}
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	return r.stationMap[stationNum].RequestSong(name, onStart)
+}
+
+// VoteSkip counts a listener's vote to skip the song playing on a station
+func (r *Radio) VoteSkip(stationNum uint16, conn net.Addr) (SkipVotes, error) {
+	if !r.stationExists(stationNum) {
+		return SkipVotes{}, fmt.Errorf("Station %d does not exist", stationNum)
+	}
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	return r.stationMap[stationNum].VoteSkip(conn)
+}
+
+// PauseStation pauses a station without disconnecting its listeners
+func (r *Radio) PauseStation(stationNum uint16) error {
+	if !r.stationExists(stationNum) {
+		return fmt.Errorf("Station %d does not exist", stationNum)
+	}
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	return r.stationMap[stationNum].Pause()
+}
+
+// ResumeStation resumes a paused station
+func (r *Radio) ResumeStation(stationNum uint16) error {
+	if !r.stationExists(stationNum) {
+		return fmt.Errorf("Station %d does not exist", stationNum)
+	}
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	return r.stationMap[stationNum].Resume()
+}
+
+// IsPaused returns true if a station is paused
+func (r *Radio) IsPaused(stationNum uint16) (bool, error) {
+	if !r.stationExists(stationNum) {
+		return false, fmt.Errorf("Station %d does not exist", stationNum)
+	}
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	return r.stationMap[stationNum].IsPaused(), nil
+}
+
+// DrainStation stops a station taking new listeners. The returned channel is closed once its current song ends,
+// which takes about the returned duration
+func (r *Radio) DrainStation(stationNum uint16) (<-chan struct{}, time.Duration, error) {
+	if !r.stationExists(stationNum) {
+		return nil, 0, fmt.Errorf("Station %d does not exist", stationNum)
+	}
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	return r.stationMap[stationNum].Drain()
+}
+
+// AddStation adds a station to the radio. A live:[feed]=[title] entry makes it a live station. The station is named
+// after its number, with a suffix if a configured station already has that name
 func (r *Radio) AddStation(songNames []string) (uint16, error) {
-	newStationNum := uint16(r.stationsIdx.Load())
-	r.stationsIdx.Inc()
-	newStation, err := CreateStation(songNames)
+	stationNum := r.nextStationNum()
+	name := fmt.Sprintf("station-%d", stationNum)
+	for suffix := 2; ; suffix++ {
+		if _, ok := r.GetStationByName(name); !ok {
+			break
+		}
+		name = fmt.Sprintf("station-%d-%d", stationNum, suffix)
+	}
+	stationConfig, err := config.StationFromEntries(name, songNames)
 	if err != nil {
 		return 0, err
 	}
-	go newStation.StartStation()
+	id := int(stationNum)
+	stationConfig.ID = &id
+	return r.AddStationFromConfig(stationConfig)
+}
+
+// AddStationFromConfig adds a configured station to the radio. Station names must be unique
+func (r *Radio) AddStationFromConfig(stationConfig config.StationConfig) (uint16, error) {
+	if _, ok := r.GetStationByName(stationConfig.Name); ok {
+		return 0, fmt.Errorf("Station %s already exists", stationConfig.Name)
+	}
+	var newStationNum uint16
+	if stationConfig.ID != nil {
+		newStationNum = uint16(*stationConfig.ID)
+		if r.stationExists(newStationNum) {
+			return 0, fmt.Errorf("Station %d already exists", newStationNum)
+		}
+	} else {
+		newStationNum = r.nextStationNum()
+	}
+	newStation, err := CreateStationFromConfig(stationConfig)
+	if err != nil {
+		return 0, err
+	}
+	newStation.warnChan = r.warnChan
 	r.stationMapMutex.Lock()
+	if _, ok := r.stationMap[newStationNum]; ok {
+		r.stationMapMutex.Unlock()
+		newStation.quitStation()
+		return 0, fmt.Errorf("Station %d already exists", newStationNum)
+	}
 	r.stationMap[newStationNum] = newStation
 	r.stationMapMutex.Unlock()
+	go newStation.StartStation()
 	r.numStations.Inc()
 	return newStationNum, nil
 }
 
+// nextStationNum takes the next station number that is free. Numbers of removed stations aren't given out again
+func (r *Radio) nextStationNum() uint16 {
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	for {
+		stationNum := uint16(r.stationsIdx.Inc() - 1)
+		if _, ok := r.stationMap[stationNum]; !ok {
+			return stationNum
+		}
+	}
+}
+
+// ListStations describes every station in order of station number
+func (r *Radio) ListStations() []StationInfo {
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	stations := make([]StationInfo, 0)
+	for stationNum, station := range r.stationMap {
+		stations = append(stations, StationInfo{
+			Number:      stationNum,
+			Name:        station.GetName(),
+			Description: station.GetDescription(),
+			Song:        station.GetCurrentSong(),
+			Listeners:   station.GetNumListeners(),
+		})
+	}
+	sort.Slice(stations, func(i, j int) bool {
+		return stations[i].Number < stations[j].Number
+	})
+	return stations
+}
+
+// GetAllStationSongs returns the songs and the song playing of every station in order of station number
+func (r *Radio) GetAllStationSongs() []StationSongs {
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	stations := make([]StationSongs, 0)
+	for stationNum, station := range r.stationMap {
+		stations = append(stations, StationSongs{
+			Number:     stationNum,
+			Name:       station.GetName(),
+			NowPlaying: station.GetCurrentSong(),
+			Songs:      station.GetStationSongs(),
+		})
+	}
+	sort.Slice(stations, func(i, j int) bool {
+		return stations[i].Number < stations[j].Number
+	})
+	return stations
+}
+
+// SearchSongs finds the songs on every station whose name or tags contain the query, in order of station number
+// and position. At most limit matches are returned
+func (r *Radio) SearchSongs(query string, limit int) []SongMatch {
+	stationNums := r.GetStationNumbers()
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	matches := make([]SongMatch, 0)
+	for _, stationNum := range stationNums {
+		station, ok := r.stationMap[stationNum]
+		if !ok {
+			continue
+		}
+		for _, match := range station.SearchSongs(query) {
+			if len(matches) >= limit {
+				return matches
+			}
+			match.Station = stationNum
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
 // RemoveStation removes a station from the radio
 func (r *Radio) RemoveStation(stationNum uint16) error {
 	if r.numStations.Load() == 0 {
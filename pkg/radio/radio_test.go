@@ -4,17 +4,28 @@ import (
 	"net"
 	"strings"
 	"testing"
+
+	"github.com/IMaloney/snowcast/pkg/config"
 )
 
+// createConfig builds a radio config the same way the command line does
+func createConfig(t *testing.T, files []string, extraCredit bool) *config.Config {
+	cfg, err := config.FromArgs("8888", files, extraCredit)
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	return cfg
+}
+
 func TestCreateRadio(t *testing.T) {
 	song1 := "../../mp3/VanillaIce-IceIceBaby.mp3"
 	song2 := "../../mp3/tinyfile"
 	song3 := "../../mp3/mediumfile"
-	_, err := CreateRadio([]string{"poop", "pee"}, false)
+	_, err := CreateRadio(createConfig(t, []string{"poop", "pee"}, false))
 	if err == nil {
 		t.Errorf("expected: error, received: nil")
 	}
-	r, err := CreateRadio([]string{song1, song2, song3}, false)
+	r, err := CreateRadio(createConfig(t, []string{song1, song2, song3}, false))
 	if err != nil {
 		t.Errorf("expected: nil, received: %v", err)
 	}
@@ -29,11 +40,11 @@ func TestCreateRadioExtraCredit(t *testing.T) {
 	song1 := "../../mp3/VanillaIce-IceIceBaby.mp3"
 	song2 := "../../mp3/tinyfile"
 	song3 := "../../mp3/mediumfile"
-	_, err := CreateRadio([]string{"poop", "pee"}, true)
+	_, err := CreateRadio(createConfig(t, []string{"poop", "pee"}, true))
 	if err == nil {
 		t.Errorf("expected: error, received: nil")
 	}
-	r, err := CreateRadio([]string{strings.Join([]string{song1, song2, song3}, ",")}, true)
+	r, err := CreateRadio(createConfig(t, []string{strings.Join([]string{song1, song2, song3}, ",")}, true))
 	if err != nil {
 		t.Errorf("expected: nil, received: %v", err)
 	}
@@ -54,7 +65,7 @@ func TestGetNumStations(t *testing.T) {
 	song1 := "../../mp3/VanillaIce-IceIceBaby.mp3"
 	song2 := "../../mp3/tinyfile"
 	song3 := "../../mp3/mediumfile"
-	r, err := CreateRadio([]string{song1, song2, song3}, false)
+	r, err := CreateRadio(createConfig(t, []string{song1, song2, song3}, false))
 	if err != nil {
 		t.Errorf("expected: nil, received: %v", err)
 	}
@@ -68,7 +79,7 @@ func TestJoinStation(t *testing.T) {
 	song1 := "../../mp3/VanillaIce-IceIceBaby.mp3"
 	song2 := "../../mp3/tinyfile"
 	song3 := "../../mp3/mediumfile"
-	r, err := CreateRadio([]string{song1, song2, song3}, false)
+	r, err := CreateRadio(createConfig(t, []string{song1, song2, song3}, false))
 	if err != nil {
 		t.Errorf("expected: nil, received: %v", err)
 	}
@@ -98,7 +109,7 @@ func TestLeaveStation(t *testing.T) {
 	song1 := "../../mp3/VanillaIce-IceIceBaby.mp3"
 	song2 := "../../mp3/tinyfile"
 	song3 := "../../mp3/mediumfile"
-	r, err := CreateRadio([]string{song1, song2, song3}, false)
+	r, err := CreateRadio(createConfig(t, []string{song1, song2, song3}, false))
 	if err != nil {
 		t.Errorf("expected: nil, received: %v", err)
 	}
@@ -133,7 +144,7 @@ func TestGetSongName(t *testing.T) {
 	song1 := "../../mp3/VanillaIce-IceIceBaby.mp3"
 	song2 := "../../mp3/tinyfile"
 	song3 := "../../mp3/mediumfile"
-	r, err := CreateRadio([]string{song1, song2, song3}, false)
+	r, err := CreateRadio(createConfig(t, []string{song1, song2, song3}, false))
 	if err != nil {
 		t.Errorf("expected: nil, received: %v", err)
 	}
@@ -150,7 +161,7 @@ func TestStationExists(t *testing.T) {
 	song1 := "../../mp3/VanillaIce-IceIceBaby.mp3"
 	song2 := "../../mp3/tinyfile"
 	song3 := "../../mp3/mediumfile"
-	r, err := CreateRadio([]string{song1, song2, song3}, false)
+	r, err := CreateRadio(createConfig(t, []string{song1, song2, song3}, false))
 	if err != nil {
 		t.Errorf("expected: nil, received: %v", err)
 	}
@@ -167,7 +178,7 @@ func TestGetStationSongs(t *testing.T) {
 	song1 := "../../mp3/VanillaIce-IceIceBaby.mp3"
 	song2 := "../../mp3/tinyfile"
 	song3 := "../../mp3/mediumfile"
-	r, err := CreateRadio([]string{strings.Join([]string{song1, song2, song3}, ",")}, true)
+	r, err := CreateRadio(createConfig(t, []string{strings.Join([]string{song1, song2, song3}, ",")}, true))
 	if err != nil {
 		t.Errorf("expected: nil, received: %v", err)
 	}
@@ -185,7 +196,7 @@ func TestAddStation(t *testing.T) {
 	song1 := "../../mp3/VanillaIce-IceIceBaby.mp3"
 	song2 := "../../mp3/tinyfile"
 	song3 := "../../mp3/mediumfile"
-	r, err := CreateRadio([]string{song1}, false)
+	r, err := CreateRadio(createConfig(t, []string{song1}, false))
 	if err != nil {
 		t.Errorf("expected: nil, received: %v", err)
 	}
@@ -206,7 +217,7 @@ func TestAddStation(t *testing.T) {
 func TestRemoveStation(t *testing.T) {
 	song1 := "../../mp3/VanillaIce-IceIceBaby.mp3"
 	song2 := "../../mp3/tinyfile"
-	r, err := CreateRadio([]string{song1, song2}, false)
+	r, err := CreateRadio(createConfig(t, []string{song1, song2}, false))
 	if err != nil {
 		t.Errorf("expected: nil, received: %v", err)
 	}
@@ -226,7 +237,7 @@ func TestRemoveStation(t *testing.T) {
 func TestRadioQuit(t *testing.T) {
 	song1 := "../../mp3/VanillaIce-IceIceBaby.mp3"
 	song2 := "../../mp3/tinyfile"
-	r, err := CreateRadio([]string{song1, song2}, false)
+	r, err := CreateRadio(createConfig(t, []string{song1, song2}, false))
 	if err != nil {
 		t.Errorf("expected: nil, received: %v", err)
 	}
@@ -235,3 +246,96 @@ func TestRadioQuit(t *testing.T) {
 		t.Errorf("expected: 0, received: %d", len(r.stationMap))
 	}
 }
+
+func TestStationIDs(t *testing.T) {
+	song1 := "../../mp3/tinyfile"
+	song2 := "../../mp3/mediumfile"
+	id := 1
+	cfg := &config.Config{
+		Listen: ":8888",
+		Stations: []config.StationConfig{
+			{Name: "a", Songs: []string{song1}},
+			{Name: "b", Songs: []string{song2}, ID: &id, Description: "the b side"},
+			{Name: "c", Songs: []string{song2}},
+		},
+	}
+	r, err := CreateRadio(cfg)
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer r.Quit()
+	stations := r.ListStations()
+	expected := []StationInfo{
+		{Number: 0, Name: "a", Song: song1},
+		{Number: 1, Name: "b", Description: "the b side", Song: song2},
+		{Number: 2, Name: "c", Song: song2},
+	}
+	if len(stations) != len(expected) {
+		t.Fatalf("expected: %d, received: %d", len(expected), len(stations))
+	}
+	for idx := range expected {
+		if stations[idx] != expected[idx] {
+			t.Errorf("expected: %v, received: %v", expected[idx], stations[idx])
+		}
+	}
+	// numbers of removed stations aren't given out again
+	err = r.RemoveStation(2)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	newNum, err := r.AddStation([]string{song1})
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	if newNum != 3 {
+		t.Errorf("expected: 3, received: %d", newNum)
+	}
+	_, err = r.AddStationFromConfig(config.StationConfig{Name: "d", Songs: []string{song1}, ID: &id})
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+}
+
+func TestAddStationName(t *testing.T) {
+	song1 := "../../mp3/tinyfile"
+	cfg := &config.Config{
+		Listen:   ":8888",
+		Stations: []config.StationConfig{{Name: "station-1", Songs: []string{song1}}},
+	}
+	r, err := CreateRadio(cfg)
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer r.Quit()
+	newNum, err := r.AddStation([]string{song1})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	if newNum != 1 {
+		t.Errorf("expected: 1, received: %d", newNum)
+	}
+	// the name follows the number and steps around a configured station that took it
+	if num, ok := r.GetStationByName("station-1-2"); !ok || num != newNum {
+		t.Errorf("expected: station-1-2 on %d, received: %d %t", newNum, num, ok)
+	}
+}
+
+func TestGetAllStationSongs(t *testing.T) {
+	song1 := "../../mp3/tinyfile"
+	song2 := "../../mp3/mediumfile"
+	r, err := CreateRadio(createConfig(t, []string{strings.Join([]string{song1, song2}, ","), song2}, true))
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer r.Quit()
+	stations := r.GetAllStationSongs()
+	if len(stations) != 2 {
+		t.Fatalf("expected: 2, received: %d", len(stations))
+	}
+	if stations[0].Number != 0 || len(stations[0].Songs) != 2 || stations[0].NowPlaying != song1 {
+		t.Errorf("expected: station 0 playing %s of 2 songs, received: %v", song1, stations[0])
+	}
+	if stations[1].Number != 1 || len(stations[1].Songs) != 1 || stations[1].NowPlaying != song2 {
+		t.Errorf("expected: station 1 playing %s of 1 song, received: %v", song2, stations[1])
+	}
+}
@@ -0,0 +1,85 @@
+package radio
+
+import (
+	"fmt"
+
+	"github.com/IMaloney/snowcast/pkg/utils"
+)
+
+// songRequest is a song a listener asked the station to play next
+type songRequest struct {
+	song *Song
+	// called once the song starts playing
+	onStart func()
+}
+
+// RequestSong queues a song of the station to play at the next song boundary, after any songs requested before it.
+// onStart is called when the song starts. Returns the place of the song in the queue, starting at 1
+func (s *Station) RequestSong(name string, onStart func()) (int, error) {
+	s.songsMutex.Lock()
+	defer s.songsMutex.Unlock()
+	var song *Song
+	for _, stationSong := range s.songs {
+		if stationSong.GetSongName() == name {
+			song = stationSong
+			break
+		}
+	}
+	switch {
+	case song == nil:
+		return 0, fmt.Errorf("Song %s is not on the station", name)
+	case s.stopped:
+		return 0, fmt.Errorf("The station has finished playing its songs")
+	case s.isUnplayable(song):
+		return 0, fmt.Errorf("Song %s can't be played right now", name)
+	case len(s.requests) >= utils.REQUESTQUEUE:
+		return 0, fmt.Errorf("The station already has %d requests waiting", utils.REQUESTQUEUE)
+	}
+	for _, request := range s.requests {
+		if request.song == song {
+			return 0, fmt.Errorf("Song %s has already been requested", name)
+		}
+	}
+	s.requests = append(s.requests, &songRequest{
+		song:    song,
+		onStart: onStart,
+	})
+	return len(s.requests), nil
+}
+
+// GetRequests returns the requested songs waiting to play, in order
+func (s *Station) GetRequests() []string {
+	s.songsMutex.RLock()
+	defer s.songsMutex.RUnlock()
+	songs := make([]string, 0)
+	for _, request := range s.requests {
+		songs = append(songs, request.song.GetSongName())
+	}
+	return songs
+}
+
+// popRequest takes the next request that can be played off the queue, nil if there is none. songsMutex must be held
+func (s *Station) popRequest() *songRequest {
+	for len(s.requests) > 0 {
+		request := s.requests[0]
+		s.requests = s.requests[1:]
+		if !s.isUnplayable(request.song) {
+			return request
+		}
+	}
+	return nil
+}
+
+// forgetRequests drops requests for songs that are no longer on the station. songsMutex must be held
+func (s *Station) forgetRequests(onStation map[*Song]bool) {
+	requests := make([]*songRequest, 0)
+	for _, request := range s.requests {
+		if onStation[request.song] {
+			requests = append(requests, request)
+		}
+	}
+	s.requests = requests
+	if s.requested != nil && !onStation[s.requested.song] {
+		s.requested = nil
+	}
+}
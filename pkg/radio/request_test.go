@@ -0,0 +1,75 @@
+package radio
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRequestSong(t *testing.T) {
+	udpAddr, err := net.ResolveUDPAddr("udp", ":4448")
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	udpConn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	defer udpConn.Close()
+	subscriber := CreateSubscriber(udpConn)
+
+	song1 := "../../mp3/FX-Impact193.mp3"
+	song2 := "../../mp3/mediumfile"
+	song3 := "../../mp3/tinyfile"
+	station, err := CreateStation([]string{song1, song2, song3})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	_, err = station.RequestSong("../../mp3/missing", nil)
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	started := make(chan struct{}, 1)
+	position, err := station.RequestSong(song3, func() {
+		started <- struct{}{}
+	})
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	if position != 1 {
+		t.Errorf("expected: 1, received: %d", position)
+	}
+	_, err = station.RequestSong(song3, nil)
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	// requests come before the planned songs
+	upcoming := station.GetUpcomingSongs(2)
+	if len(upcoming) != 2 || upcoming[0] != song3 || upcoming[1] != song2 {
+		t.Errorf("expected: [%s %s], received: %v", song3, song2, upcoming)
+	}
+
+	station.subscribe(udpConn.RemoteAddr(), subscriber)
+	go station.StartStation()
+	defer station.Quit()
+	err = station.Skip()
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	select {
+	case name := <-subscriber.ChangeSong:
+		if name != song3 {
+			t.Errorf("expected: %s, received: %s", song3, name)
+		}
+	case <-time.After(3 * time.Second):
+		t.Errorf("expected: %s, received: nothing", song3)
+	}
+	select {
+	case <-started:
+	case <-time.After(3 * time.Second):
+		t.Errorf("expected: request to start, received: nothing")
+	}
+	if len(station.GetRequests()) != 0 {
+		t.Errorf("expected: 0, received: %d", len(station.GetRequests()))
+	}
+}
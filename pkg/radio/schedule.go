@@ -0,0 +1,197 @@
+package radio
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/IMaloney/snowcast/pkg/config"
+	"github.com/IMaloney/snowcast/pkg/utils"
+)
+
+type pendingShow struct {
+	name  string
+	songs []*Song
+}
+
+// SetSchedule sets the shows of the station and the songs it plays between shows. A changed schedule is checked again
+// so the station picks up new show songs at the next song boundary
+func (s *Station) SetSchedule(cfg config.StationConfig) {
+	s.songsMutex.Lock()
+	defer s.songsMutex.Unlock()
+	if !reflect.DeepEqual(s.schedule, cfg.Schedule) {
+		s.scheduleChanged = true
+	}
+	s.schedule = cfg.Schedule
+	s.regular = config.StationConfig{
+		Songs:      cfg.Songs,
+		Playlist:   cfg.Playlist,
+		Directory:  cfg.Directory,
+		Categories: cfg.Categories,
+	}
+}
+
+// GetShow returns the name of the show on the station, empty between shows
+func (s *Station) GetShow() string {
+	s.songsMutex.RLock()
+	defer s.songsMutex.RUnlock()
+	return s.show
+}
+
+// GetShows returns the show that is on at the given time and the next show to start after it. The next show
+// is empty if the station has no shows
+func (s *Station) GetShows(now time.Time) (string, string, time.Time) {
+	s.songsMutex.RLock()
+	defer s.songsMutex.RUnlock()
+	current := ""
+	if show, ok := activeShow(s.schedule, now); ok {
+		current = show.Name
+	}
+	next, start, ok := nextShow(s.schedule, now)
+	if !ok {
+		return current, "", time.Time{}
+	}
+	return current, next.Name, start
+}
+
+// watchSchedule checks which show should be on until the station quits
+func (s *Station) watchSchedule() {
+	ticker := time.NewTicker(utils.SCHEDULETIME * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.doneChan:
+			return
+		case <-ticker.C:
+			s.checkSchedule(time.Now())
+		}
+	}
+}
+
+// checkSchedule loads the songs of the show that should be on at the given time. The station switches to them
+// at the next song boundary
+func (s *Station) checkSchedule(now time.Time) error {
+	s.songsMutex.Lock()
+	target := ""
+	source := s.regular
+	if show, ok := activeShow(s.schedule, now); ok {
+		target = show.Name
+		source = config.StationConfig{Songs: show.Songs, Playlist: show.Playlist, Directory: show.Directory}
+	}
+	upToDate := target == s.show && !s.scheduleChanged
+	if s.pending != nil {
+		upToDate = target == s.pending.name && !s.scheduleChanged
+	}
+	s.scheduleChanged = false
+	s.songsMutex.Unlock()
+	if upToDate {
+		return nil
+	}
+	names, err := expandSongs(source)
+	if err != nil {
+		return err
+	}
+	songs := make([]*Song, 0)
+	for _, name := range names {
+		song, err := CreateSong(name)
+		if err != nil {
+			for _, song := range songs {
+				song.EndSong()
+			}
+			return err
+		}
+		songs = append(songs, song)
+	}
+	s.songsMutex.Lock()
+	replaced := s.pending
+	s.pending = &pendingShow{
+		name:  target,
+		songs: songs,
+	}
+	s.songsMutex.Unlock()
+	if replaced != nil {
+		for _, song := range replaced.songs {
+			song.EndSong()
+		}
+	}
+	return nil
+}
+
+// startShow swaps in the songs of the pending show and returns the songs it replaced. songsMutex must be held
+func (s *Station) startShow() []*Song {
+	ended := s.songs
+	s.songs = s.pending.songs
+	s.show = s.pending.name
+	s.pending = nil
+	s.numSongs.Store(uint64(len(s.songs)))
+	s.currentSong = 0
+	s.upcoming = make([]*Song, 0)
+	s.history = make([]*Song, 0)
+	s.clockSlot = 0
+	s.stopped = false
+	s.unplayable = make(map[*Song]time.Time)
+	s.silent = false
+	// requested songs belong to the songs the show replaced
+	s.requests = make([]*songRequest, 0)
+	s.requested = nil
+	return ended
+}
+
+// activeShow returns the show that is on at the given time. Shows earlier in the schedule win when shows overlap
+func activeShow(schedule []config.ShowConfig, now time.Time) (config.ShowConfig, bool) {
+	minute := now.Hour()*60 + now.Minute()
+	for _, show := range schedule {
+		start, _ := config.ParseTimeOfDay(show.Start)
+		end, _ := config.ParseTimeOfDay(show.End)
+		switch {
+		case start < end && minute >= start && minute < end:
+			if showsOn(show, now.Weekday()) {
+				return show, true
+			}
+		case start > end && minute >= start:
+			if showsOn(show, now.Weekday()) {
+				return show, true
+			}
+		case start > end && minute < end:
+			// the show started the day before and runs past midnight
+			if showsOn(show, now.AddDate(0, 0, -1).Weekday()) {
+				return show, true
+			}
+		}
+	}
+	return config.ShowConfig{}, false
+}
+
+// nextShow returns the next show to start after the given time and when it starts
+func nextShow(schedule []config.ShowConfig, now time.Time) (config.ShowConfig, time.Time, bool) {
+	var next config.ShowConfig
+	var nextStart time.Time
+	found := false
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for _, show := range schedule {
+		start, _ := config.ParseTimeOfDay(show.Start)
+		for offset := 0; offset <= 7; offset++ {
+			startTime := midnight.AddDate(0, 0, offset).Add(time.Duration(start) * time.Minute)
+			if !startTime.After(now) || !showsOn(show, startTime.Weekday()) {
+				continue
+			}
+			if !found || startTime.Before(nextStart) {
+				next, nextStart, found = show, startTime, true
+			}
+			break
+		}
+	}
+	return next, nextStart, found
+}
+
+// showsOn returns true if the show starts on the given day
+func showsOn(show config.ShowConfig, day time.Weekday) bool {
+	if len(show.Days) == 0 {
+		return true
+	}
+	for _, name := range show.Days {
+		if showDay, err := config.ParseDay(name); err == nil && showDay == day {
+			return true
+		}
+	}
+	return false
+}
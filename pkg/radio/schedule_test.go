@@ -0,0 +1,99 @@
+package radio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/IMaloney/snowcast/pkg/config"
+)
+
+func TestActiveShow(t *testing.T) {
+	schedule := []config.ShowConfig{
+		{Name: "focus", Start: "09:00", End: "12:00"},
+		{Name: "late", Start: "22:00", End: "02:00", Days: []string{"fri"}},
+	}
+	// a monday
+	monday := time.Date(2021, time.March, 1, 10, 30, 0, 0, time.Local)
+	show, ok := activeShow(schedule, monday)
+	if !ok || show.Name != "focus" {
+		t.Errorf("expected: focus, received: %s", show.Name)
+	}
+	if _, ok := activeShow(schedule, monday.Add(2*time.Hour)); ok {
+		t.Errorf("expected: no show, received: a show")
+	}
+	friday := time.Date(2021, time.March, 5, 23, 0, 0, 0, time.Local)
+	if show, ok := activeShow(schedule, friday); !ok || show.Name != "late" {
+		t.Errorf("expected: late, received: %s", show.Name)
+	}
+	// the late show runs past midnight into saturday but doesn't start on saturday night
+	if show, ok := activeShow(schedule, friday.Add(2*time.Hour)); !ok || show.Name != "late" {
+		t.Errorf("expected: late, received: %s", show.Name)
+	}
+	if _, ok := activeShow(schedule, friday.Add(24*time.Hour)); ok {
+		t.Errorf("expected: no show, received: a show")
+	}
+}
+
+func TestNextShow(t *testing.T) {
+	schedule := []config.ShowConfig{
+		{Name: "focus", Start: "09:00", End: "12:00"},
+		{Name: "upbeat", Start: "13:00", End: "17:00", Days: []string{"mon"}},
+	}
+	monday := time.Date(2021, time.March, 1, 10, 30, 0, 0, time.Local)
+	show, start, ok := nextShow(schedule, monday)
+	if !ok || show.Name != "upbeat" {
+		t.Errorf("expected: upbeat, received: %s", show.Name)
+	}
+	if start.Hour() != 13 || start.Day() != 1 {
+		t.Errorf("expected: 13:00 on the 1st, received: %v", start)
+	}
+	show, start, _ = nextShow(schedule, monday.Add(6*time.Hour))
+	if show.Name != "focus" || start.Day() != 2 {
+		t.Errorf("expected: focus on the 2nd, received: %s on the %d", show.Name, start.Day())
+	}
+	if _, _, ok := nextShow(nil, monday); ok {
+		t.Errorf("expected: no show, received: a show")
+	}
+}
+
+func TestShowSwapsAtSongBoundary(t *testing.T) {
+	regular := "../../mp3/tinyfile"
+	showSong := "../../mp3/mediumfile"
+	now := time.Now()
+	start := now.Add(time.Hour).Format("15:04")
+	end := now.Add(2 * time.Hour).Format("15:04")
+	cfg := config.StationConfig{
+		Name:     "scheduled",
+		Songs:    []string{regular},
+		Schedule: []config.ShowConfig{{Name: "focus", Start: start, End: end, Songs: []string{showSong}}},
+	}
+	station, err := CreateStationFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer station.quitStation()
+	if station.GetShow() != "" || station.GetCurrentSong() != regular {
+		t.Errorf("expected: %s, received: %s", regular, station.GetCurrentSong())
+	}
+	current, next, _ := station.GetShows(now)
+	if current != "" || next != "focus" {
+		t.Errorf("expected: focus next, received: %s now and %s next", current, next)
+	}
+	err = station.checkSchedule(now.Add(90 * time.Minute))
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	// the show waits for the song to finish
+	if station.GetCurrentSong() != regular {
+		t.Errorf("expected: %s, received: %s", regular, station.GetCurrentSong())
+	}
+	station.nextSong(station.songs[station.currentSong])
+	if station.GetShow() != "focus" || station.GetCurrentSong() != showSong {
+		t.Errorf("expected: %s, received: %s", showSong, station.GetCurrentSong())
+	}
+	station.checkSchedule(now.Add(3 * time.Hour))
+	station.nextSong(station.songs[station.currentSong])
+	if station.GetShow() != "" || station.GetCurrentSong() != regular {
+		t.Errorf("expected: %s, received: %s", regular, station.GetCurrentSong())
+	}
+}
@@ -0,0 +1,60 @@
+package radio
+
+import (
+	"sort"
+	"strings"
+)
+
+// SongMatch is a song found by a search
+type SongMatch struct {
+	Station uint16
+	Song    string
+	// index of the song in the station songs
+	Position int
+	// true if the song is playing on the station right now
+	OnAir bool
+	// categories the song is tagged with
+	Tags []string
+}
+
+// SearchSongs finds the songs of the station whose name or tags contain the query, ignoring case
+func (s *Station) SearchSongs(query string) []SongMatch {
+	query = strings.ToLower(query)
+	liveOnAir := s.onAir.Load()
+	paused := s.paused.Load()
+	s.songsMutex.RLock()
+	defer s.songsMutex.RUnlock()
+	tags := make(map[string][]string)
+	for category, songs := range s.categories {
+		for _, song := range songs {
+			tags[song] = append(tags[song], category)
+		}
+	}
+	matches := make([]SongMatch, 0)
+	for idx, song := range s.songs {
+		name := song.GetSongName()
+		songTags := tags[name]
+		sort.Strings(songTags)
+		if !strings.Contains(strings.ToLower(name), query) && !containsTag(songTags, query) {
+			continue
+		}
+		onAir := idx == s.currentSong && !liveOnAir && !paused && !s.stopped && !s.silent && s.clip == nil
+		matches = append(matches, SongMatch{
+			Song:     name,
+			Position: idx,
+			OnAir:    onAir,
+			Tags:     append(make([]string, 0), songTags...),
+		})
+	}
+	return matches
+}
+
+// containsTag returns true if one of the tags contains the lower case query
+func containsTag(tags []string, query string) bool {
+	for _, tag := range tags {
+		if strings.Contains(strings.ToLower(tag), query) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package radio
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/IMaloney/snowcast/pkg/config"
+)
+
+func TestSearchSongs(t *testing.T) {
+	song1 := "../../mp3/tinyfile"
+	song2 := "../../mp3/mediumfile"
+	song3 := "../../mp3/FX-Impact193.mp3"
+	cfg := &config.Config{
+		Listen: ":8888",
+		Stations: []config.StationConfig{
+			{Name: "a", Songs: []string{song1, song2}},
+			{Name: "b", Songs: []string{song2}, Categories: map[string][]config.CategorySong{
+				"Jingle": {{Song: song3}},
+			}},
+		},
+	}
+	r, err := CreateRadio(cfg)
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer r.Quit()
+
+	matches := r.SearchSongs("MEDIUM", 10)
+	if len(matches) != 2 {
+		t.Fatalf("expected: 2, received: %d", len(matches))
+	}
+	if matches[0].Station != 0 || matches[0].Position != 1 || matches[0].OnAir {
+		t.Errorf("expected: station 0 position 1 off air, received: %v", matches[0])
+	}
+	if matches[1].Station != 1 || matches[1].Position != 0 || !matches[1].OnAir {
+		t.Errorf("expected: station 1 position 0 on air, received: %v", matches[1])
+	}
+
+	// tags are searched too
+	matches = r.SearchSongs("jingle", 10)
+	if len(matches) != 1 || matches[0].Song != song3 || strings.Join(matches[0].Tags, ",") != "Jingle" {
+		t.Errorf("expected: %s tagged Jingle, received: %v", song3, matches)
+	}
+
+	matches = r.SearchSongs("mp3", 2)
+	if len(matches) != 2 {
+		t.Errorf("expected: 2, received: %d", len(matches))
+	}
+	matches = r.SearchSongs("nothing like it", 10)
+	if len(matches) != 0 {
+		t.Errorf("expected: 0, received: %d", len(matches))
+	}
+}
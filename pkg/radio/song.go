@@ -3,13 +3,16 @@ package radio
 import (
 	"io"
 	"os"
+	"sync"
 
 	"github.com/IMaloney/snowcast/pkg/utils"
 )
 
 type Song struct {
 	name string
-	file *os.File
+	// guards file, which Reopen swaps while the station may be reading from it
+	fileMutex sync.RWMutex
+	file      *os.File
 }
 
 type SongData struct {
@@ -35,6 +38,8 @@ func (s *Song) GetSongName() string {
 
 // GetSongDataChunk returns up to utils.SONGCHUNK data from the song. If the file is at its end, an error is returned
 func (s *Song) GetSongDataChunk() (*SongData, error) {
+	s.fileMutex.RLock()
+	defer s.fileMutex.RUnlock()
 	buffer := make([]byte, utils.SONGCHUNK)
 	n, err := s.file.Read(buffer)
 	if err != nil {
@@ -47,9 +52,56 @@ func (s *Song) GetSongDataChunk() (*SongData, error) {
 }
 
 func (s *Song) EndSong() {
+	s.fileMutex.RLock()
+	defer s.fileMutex.RUnlock()
 	s.file.Close()
 }
 
 func (s *Song) ResetSong() {
+	s.fileMutex.RLock()
+	defer s.fileMutex.RUnlock()
 	s.file.Seek(0, io.SeekStart)
 }
+
+// GetPosition returns how far into the song file playback is
+func (s *Song) GetPosition() int64 {
+	s.fileMutex.RLock()
+	defer s.fileMutex.RUnlock()
+	offset, err := s.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0
+	}
+	return offset
+}
+
+// SeekTo moves playback to the offset in the song file
+func (s *Song) SeekTo(offset int64) error {
+	s.fileMutex.RLock()
+	defer s.fileMutex.RUnlock()
+	_, err := s.file.Seek(offset, io.SeekStart)
+	return err
+}
+
+// GetSize returns the size of the song file
+func (s *Song) GetSize() int64 {
+	s.fileMutex.RLock()
+	defer s.fileMutex.RUnlock()
+	info, err := s.file.Stat()
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// Reopen opens the song file again from the top
+func (s *Song) Reopen() error {
+	file, err := os.Open(s.name)
+	if err != nil {
+		return err
+	}
+	s.fileMutex.Lock()
+	defer s.fileMutex.Unlock()
+	s.file.Close()
+	s.file = file
+	return nil
+}
@@ -73,3 +73,18 @@ func TestResetSong(t *testing.T) {
 		t.Errorf("expected: %d, received: %d", 0, val)
 	}
 }
+
+func TestSeekTo(t *testing.T) {
+	songName := "../../mp3/mediumfile"
+	s, err := CreateSong(songName)
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer s.EndSong()
+	if err := s.SeekTo(100); err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	if s.GetPosition() != 100 {
+		t.Errorf("expected: %d, received: %d", 100, s.GetPosition())
+	}
+}
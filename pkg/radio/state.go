@@ -0,0 +1,80 @@
+package radio
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+type stationState struct {
+	Song     int    `json:"song"`
+	SongName string `json:"songName"`
+	Offset   int64  `json:"offset"`
+}
+
+type radioState struct {
+	// station name to where the station was
+	Stations map[string]stationState `json:"stations"`
+}
+
+// SaveState writes the current song and offset of every station to the state file
+func (r *Radio) SaveState(path string) error {
+	state := radioState{
+		Stations: make(map[string]stationState),
+	}
+	r.stationMapMutex.RLock()
+	for _, station := range r.stationMap {
+		songIdx, songName, offset := station.GetPosition()
+		state.Stations[station.GetName()] = stationState{
+			Song:     songIdx,
+			SongName: songName,
+			Offset:   offset,
+		}
+	}
+	r.stationMapMutex.RUnlock()
+	data, err := json.MarshalIndent(state, "", "    ")
+	if err != nil {
+		return err
+	}
+	// writing to a temporary file first so a crash never leaves half a state file
+	tmpFile, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("could not save state. Error: %v", err)
+	}
+	_, err = tmpFile.Write(data)
+	tmpFile.Close()
+	if err != nil {
+		os.Remove(tmpFile.Name())
+		return fmt.Errorf("could not save state. Error: %v", err)
+	}
+	return os.Rename(tmpFile.Name(), path)
+}
+
+// RestoreState moves every station to where the state file says it was. A missing state file restores nothing
+func (r *Radio) RestoreState(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read state %s. Error: %v", path, err)
+	}
+	state := radioState{}
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		return fmt.Errorf("could not parse state %s. Error: %v", path, err)
+	}
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	for _, station := range r.stationMap {
+		saved, ok := state.Stations[station.GetName()]
+		if !ok {
+			continue
+		}
+		// a station whose songs changed since the save just starts from the top
+		station.RestorePosition(saved.Song, saved.SongName, saved.Offset)
+	}
+	return nil
+}
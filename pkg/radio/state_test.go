@@ -0,0 +1,67 @@
+package radio
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/IMaloney/snowcast/pkg/config"
+)
+
+func TestSaveAndRestoreState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snowcast")
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	song := "../../mp3/tinyfile"
+	song2 := "../../mp3/mediumfile"
+	statePath := filepath.Join(dir, "state.json")
+	cfg := &config.Config{
+		Listen:    ":8888",
+		StateFile: statePath,
+		Stations: []config.StationConfig{
+			{Name: "focus", Songs: []string{song, song2}},
+		},
+	}
+	r, err := CreateRadio(cfg)
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	stationNum, _ := r.GetStationByName("focus")
+	r.stationMap[stationNum].RestorePosition(1, song2, 100)
+	err = r.SaveState(statePath)
+	r.Quit()
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+
+	restored, err := CreateRadio(cfg)
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer restored.Quit()
+	stationNum, _ = restored.GetStationByName("focus")
+	songIdx, songName, offset := restored.stationMap[stationNum].GetPosition()
+	if songIdx != 1 || songName != song2 {
+		t.Errorf("expected: 1 %s, received: %d %s", song2, songIdx, songName)
+	}
+	if offset < 100 {
+		t.Errorf("expected: at least 100, received: %d", offset)
+	}
+}
+
+func TestRestoreMissingState(t *testing.T) {
+	r, err := CreateRadio(createConfig(t, []string{"../../mp3/tinyfile"}, false))
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer r.Quit()
+	if err := r.RestoreState("../../mp3/poop.json"); err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	if err := r.RestoreState("../../mp3/tinyfile"); err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+}
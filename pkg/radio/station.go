@@ -2,37 +2,98 @@ package radio
 
 import (
 	"fmt"
+	"io"
 	"net"
+	"path/filepath"
+	"reflect"
 	"sync"
 	"time"
 
+	"github.com/IMaloney/snowcast/pkg/config"
 	"github.com/IMaloney/snowcast/pkg/utils"
 	"go.uber.org/atomic"
 )
 
 type Station struct {
 	// TODO: should use atomic package by uber
+	name            string
+	description     *atomic.String
+	liveFeed        string
 	currentSong     int
 	numSongs        atomic.Uint64
 	songs           []*Song
 	songsMutex      sync.RWMutex
 	quitChan        chan struct{}
 	subscribers     map[net.Addr]*Subscriber
+	watchers        map[net.Addr]stationWatch
 	subscriberMutex sync.RWMutex
+	live            *LiveSource
+	liveMutex       sync.RWMutex
+	onAir           *atomic.Bool
+	lastLiveData    time.Time
+	sleepTime       *atomic.Duration
+	maxListeners    *atomic.Uint32
+	mode            PlayMode
+	noRepeat        int
+	upcoming        []*Song
+	history         []*Song
+	stopped         bool
+	categories      map[string][]string
+	weights         map[string]int
+	clock           []string
+	clockSlot       int
+	schedule        []config.ShowConfig
+	regular         config.StationConfig
+	show            string
+	pending         *pendingShow
+	scheduleChanged bool
+	clips           []*Song
+	clip            *Song
+	everySongs      int
+	everyInterval   time.Duration
+	songsSinceClip  int
+	lastClip        time.Time
+	skipping        *Song
+	// the current song was removed or reloaded away, so the song now current starts without a song boundary and
+	// has to be announced
+	replaced     bool
+	skipFraction float64
+	votes        map[string]bool
+	voteSong     *Song
+	paused       *atomic.Bool
+	draining     *atomic.Bool
+	drainChan    chan struct{}
+	drainOnce    sync.Once
+	requests     []*songRequest
+	requested    *songRequest
+	unplayable   map[*Song]time.Time
+	silent       bool
+	fallback     *Song
+	warnChan     chan string
+	directory    *atomic.String
+	dirWatched   *atomic.Bool
+	doneChan     chan struct{}
+	doneOnce     sync.Once
 }
 
 type Subscriber struct {
-	udpConn    *net.UDPConn
-	ChangeSong chan string
-	EndStation chan struct{}
+	udpConn          *net.UDPConn
+	ChangeSong       chan string
+	PlayInterstitial chan string
+	PauseChange      chan bool
+	SkipVotes        chan SkipVotes
+	EndStation       chan struct{}
 }
 
 // CreateSubscriber creates a subscriber
 func CreateSubscriber(udpConn *net.UDPConn) *Subscriber {
 	return &Subscriber{
-		udpConn:    udpConn,
-		ChangeSong: make(chan string, 1),
-		EndStation: make(chan struct{}, 1),
+		udpConn:          udpConn,
+		ChangeSong:       make(chan string, 1),
+		PlayInterstitial: make(chan string, 1),
+		PauseChange:      make(chan bool, 1),
+		SkipVotes:        make(chan SkipVotes, 1),
+		EndStation:       make(chan struct{}, 1),
 	}
 }
 
@@ -49,19 +110,187 @@ func CreateStation(names []string) (*Station, error) {
 		numSongs.Inc()
 	}
 	return &Station{
-		currentSong: 0,
-		numSongs:    numSongs,
-		songs:       songs,
-		quitChan:    make(chan struct{}, 1),
-		subscribers: make(map[net.Addr]*Subscriber),
+		currentSong:  0,
+		numSongs:     numSongs,
+		songs:        songs,
+		quitChan:     make(chan struct{}, 1),
+		subscribers:  make(map[net.Addr]*Subscriber),
+		watchers:     make(map[net.Addr]stationWatch),
+		onAir:        atomic.NewBool(false),
+		paused:       atomic.NewBool(false),
+		draining:     atomic.NewBool(false),
+		drainChan:    make(chan struct{}),
+		requests:     make([]*songRequest, 0),
+		skipFraction: utils.SKIPFRACTION,
+		votes:        make(map[string]bool),
+		unplayable:   make(map[*Song]time.Time),
+		sleepTime:    atomic.NewDuration(utils.SLEEPTIME * time.Millisecond),
+		maxListeners: atomic.NewUint32(0),
+		upcoming:     make([]*Song, 0),
+		history:      make([]*Song, 0),
+		clips:        make([]*Song, 0),
+		lastClip:     time.Now(),
+		categories:   make(map[string][]string),
+		weights:      make(map[string]int),
+		directory:    atomic.NewString(""),
+		dirWatched:   atomic.NewBool(false),
+		description:  atomic.NewString(""),
+		doneChan:     make(chan struct{}),
 	}, nil
 }
 
+// CreateStationFromConfig creates a station with the songs, live feed and settings of a station config
+func CreateStationFromConfig(cfg config.StationConfig) (*Station, error) {
+	songs, err := expandSongs(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var station *Station
+	if cfg.Live == "" {
+		station, err = CreateStation(songs)
+	} else {
+		live, liveErr := CreateLiveSource(cfg.Live, cfg.LiveTitle)
+		if liveErr != nil {
+			return nil, liveErr
+		}
+		station, err = CreateLiveStation(live, songs)
+		if err != nil {
+			live.Quit()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	station.name = cfg.Name
+	station.liveFeed = cfg.Live
+	err = station.ApplySettings(cfg)
+	if err != nil {
+		station.quitStation()
+		return nil, err
+	}
+	err = station.checkSchedule(time.Now())
+	if err != nil {
+		station.quitStation()
+		return nil, err
+	}
+	// nothing has played yet so a show that is on can start right away
+	station.songsMutex.Lock()
+	var ended []*Song
+	if station.pending != nil {
+		ended = station.startShow()
+	}
+	station.songsMutex.Unlock()
+	for _, song := range ended {
+		song.EndSong()
+	}
+	return station, nil
+}
+
+// ApplySettings updates the description, pacing, listener limit, skip vote fraction, play mode, clock, schedule, interstitials and watched directory of the station
+func (s *Station) ApplySettings(cfg config.StationConfig) error {
+	mode, err := ParsePlayMode(cfg.Mode)
+	if err != nil {
+		return err
+	}
+	if cfg.Mode == "" && cfg.Shuffle {
+		mode = Shuffle
+	}
+	if cfg.Mode == "" && len(cfg.Clock) > 0 {
+		mode = Clock
+	}
+	if mode == Clock && len(cfg.Clock) == 0 {
+		return fmt.Errorf("clock mode needs a clock")
+	}
+	s.SetClock(cfg.Categories, cfg.Clock)
+	s.SetSchedule(cfg)
+	err = s.SetInterstitials(cfg.Interstitials)
+	if err != nil {
+		return err
+	}
+	err = s.SetFallback(cfg.Fallback)
+	if err != nil {
+		return err
+	}
+	s.SetSkipFraction(cfg.SkipFraction)
+	if currentMode, noRepeat := s.GetPlayMode(); currentMode != mode || noRepeat != cfg.NoRepeat {
+		s.SetPlayMode(mode, cfg.NoRepeat)
+	}
+	s.directory.Store(cfg.Directory)
+	s.description.Store(cfg.Description)
+	sleepTime := utils.SLEEPTIME * time.Millisecond
+	if cfg.SleepTime > 0 {
+		sleepTime = time.Duration(cfg.SleepTime) * time.Millisecond
+	}
+	s.sleepTime.Store(sleepTime)
+	s.maxListeners.Store(uint32(cfg.MaxListeners))
+	return nil
+}
+
+// GetName returns the name of the station
+func (s *Station) GetName() string {
+	return s.name
+}
+
+// GetDescription returns what the station plays
+func (s *Station) GetDescription() string {
+	return s.description.Load()
+}
+
+// GetLiveFeed returns the configured live feed of the station, empty if it has none
+func (s *Station) GetLiveFeed() string {
+	return s.liveFeed
+}
+
+// CreateLiveStation creates a station fed by a live source. Any songs are played as a fallback while the feed is silent
+func CreateLiveStation(live *LiveSource, names []string) (*Station, error) {
+	station, err := CreateStation(names)
+	if err != nil {
+		return nil, err
+	}
+	station.live = live
+	return station, nil
+}
+
 // GetCurrentSong returns the name of the current song playing
 func (s *Station) GetCurrentSong() string {
+	live := s.getLive()
+	if live != nil && (s.onAir.Load() || s.numSongs.Load() == 0) {
+		return live.GetTitle()
+	}
+	s.songsMutex.RLock()
+	defer s.songsMutex.RUnlock()
+	if len(s.songs) == 0 || s.stopped {
+		return ""
+	}
+	if s.silent {
+		return s.fillerTitle()
+	}
 	return s.songs[s.currentSong].GetSongName()
 }
 
+// getLive returns the live source of the station, nil if there isn't one
+func (s *Station) getLive() *LiveSource {
+	s.liveMutex.RLock()
+	defer s.liveMutex.RUnlock()
+	return s.live
+}
+
+// AttachLive puts a live source on air on the station. Errors if the station already has a live source
+func (s *Station) AttachLive(live *LiveSource) error {
+	s.liveMutex.Lock()
+	defer s.liveMutex.Unlock()
+	if s.live != nil {
+		return fmt.Errorf("station already has a live source")
+	}
+	s.live = live
+	return nil
+}
+
+// IsLive returns true if a live feed is currently on air
+func (s *Station) IsLive() bool {
+	return s.onAir.Load()
+}
+
 // AddSong adds a song to the station
 func (s *Station) AddSong(name string) error {
 	song, err := CreateSong(name)
@@ -70,12 +299,287 @@ func (s *Station) AddSong(name string) error {
 	}
 	s.songsMutex.Lock()
 	s.songs = append(s.songs, song)
+	s.forgetSongs()
 	s.songsMutex.Unlock()
 	// could switch up mutex for number with atomic package uber
 	s.numSongs.Inc()
 	return nil
 }
 
+// RemoveSong removes a song from the station. If the song is playing the station moves on to the next song
+func (s *Station) RemoveSong(name string) error {
+	s.songsMutex.Lock()
+	idx := -1
+	for i, song := range s.songs {
+		if song.GetSongName() == name {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		s.songsMutex.Unlock()
+		return fmt.Errorf("Song %s is not on the station", name)
+	}
+	song := s.songs[idx]
+	if idx == s.currentSong {
+		s.replaced = true
+	}
+	s.songs = append(s.songs[:idx], s.songs[idx+1:]...)
+	if idx < s.currentSong {
+		s.currentSong--
+	} else if s.currentSong >= len(s.songs) {
+		s.currentSong = 0
+	}
+	s.forgetSongs()
+	s.numSongs.Dec()
+	s.songsMutex.Unlock()
+	// closing the song ends it if it was playing
+	song.EndSong()
+	return nil
+}
+
+// MoveSong moves the song at index from to index to. The current song keeps playing
+func (s *Station) MoveSong(from, to int) error {
+	s.songsMutex.Lock()
+	defer s.songsMutex.Unlock()
+	if from < 0 || from >= len(s.songs) || to < 0 || to >= len(s.songs) {
+		return fmt.Errorf("Song positions must be between 0 and %d", len(s.songs)-1)
+	}
+	playing := s.songs[s.currentSong]
+	song := s.songs[from]
+	s.songs = append(s.songs[:from], s.songs[from+1:]...)
+	s.songs = append(s.songs[:to], append([]*Song{song}, s.songs[to:]...)...)
+	s.setCurrent(playing)
+	s.forgetSongs()
+	return nil
+}
+
+// Skip ends the current song or clip early and moves the station on to what plays next
+func (s *Station) Skip() error {
+	s.songsMutex.Lock()
+	defer s.songsMutex.Unlock()
+	switch {
+	case s.clip != nil:
+		s.skipping = s.clip
+	case len(s.songs) == 0 || s.stopped:
+		return fmt.Errorf("Nothing is playing on the station")
+	default:
+		s.skipping = s.songs[s.currentSong]
+	}
+	return nil
+}
+
+// Pause stops the station sending audio. Listeners stay subscribed and the station keeps its place
+func (s *Station) Pause() error {
+	if !s.paused.CAS(false, true) {
+		return fmt.Errorf("Station is already paused")
+	}
+	s.publishPause(true)
+	return nil
+}
+
+// Resume starts a paused station sending audio again from where it paused
+func (s *Station) Resume() error {
+	if !s.paused.CAS(true, false) {
+		return fmt.Errorf("Station is not paused")
+	}
+	s.publishPause(false)
+	return nil
+}
+
+// IsPaused returns true if the station is paused
+func (s *Station) IsPaused() bool {
+	return s.paused.Load()
+}
+
+// Drain stops the station taking new listeners and returns a channel that is closed once the current song ends,
+// along with about how long that will take
+func (s *Station) Drain() (<-chan struct{}, time.Duration, error) {
+	if !s.draining.CAS(false, true) {
+		return nil, 0, fmt.Errorf("Station is already shutting down")
+	}
+	return s.drainChan, s.TimeLeft(), nil
+}
+
+// IsDraining returns true if the station is shutting down
+func (s *Station) IsDraining() bool {
+	return s.draining.Load()
+}
+
+// TimeLeft returns about how long the current song has left to play. A live station has utils.DRAINTIME left
+func (s *Station) TimeLeft() time.Duration {
+	if s.onAir.Load() {
+		return utils.DRAINTIME * time.Millisecond
+	}
+	s.songsMutex.RLock()
+	defer s.songsMutex.RUnlock()
+	song := s.clip
+	if song == nil {
+		if len(s.songs) == 0 || s.stopped {
+			return 0
+		}
+		song = s.songs[s.currentSong]
+	}
+	chunksLeft := (song.GetSize() - song.GetPosition() + utils.SONGCHUNK - 1) / utils.SONGCHUNK
+	return time.Duration(chunksLeft) * s.sleepTime.Load()
+}
+
+// finishDrain tells whoever is draining the station that the current song is over
+func (s *Station) finishDrain() {
+	s.drainOnce.Do(func() {
+		close(s.drainChan)
+	})
+}
+
+// isDrained returns true once a draining station has finished its song
+func (s *Station) isDrained() bool {
+	select {
+	case <-s.drainChan:
+		return true
+	default:
+		return false
+	}
+}
+
+// startDirectoryWatch starts rescanning the directory of the station if it has one and isn't rescanned already
+func (s *Station) startDirectoryWatch() {
+	if s.directory.Load() != "" && s.dirWatched.CAS(false, true) {
+		go s.watchDirectory()
+	}
+}
+
+// watchDirectory rescans the directory of the station until the station quits
+func (s *Station) watchDirectory() {
+	ticker := time.NewTicker(utils.RESCANTIME * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.doneChan:
+			return
+		case <-ticker.C:
+			s.rescanDirectory()
+		}
+	}
+}
+
+// rescanDirectory adds files that appeared in the station directory and removes files that are gone
+func (s *Station) rescanDirectory() {
+	dir := s.directory.Load()
+	// the directory only feeds the station between shows
+	if dir == "" || s.GetShow() != "" {
+		return
+	}
+	files, err := ScanDirectory(dir)
+	if err != nil {
+		// keep playing what we have if the directory is briefly unavailable
+		return
+	}
+	found := make(map[string]bool)
+	for _, file := range files {
+		found[file] = true
+	}
+	playing := make(map[string]bool)
+	for _, song := range s.GetStationSongs() {
+		playing[song] = true
+		if filepath.Dir(song) == filepath.Clean(dir) && !found[song] {
+			s.RemoveSong(song)
+		}
+	}
+	for _, file := range files {
+		if !playing[file] {
+			s.AddSong(file)
+		}
+	}
+}
+
+// SetSongs replaces the playlist of the station. Songs kept from the old playlist carry on from where they were
+func (s *Station) SetSongs(names []string) error {
+	s.songsMutex.RLock()
+	existing := make(map[string]*Song)
+	for _, song := range s.songs {
+		existing[song.GetSongName()] = song
+	}
+	s.songsMutex.RUnlock()
+	songs := make([]*Song, 0)
+	created := make([]*Song, 0)
+	for _, name := range names {
+		if song, ok := existing[name]; ok {
+			songs = append(songs, song)
+			delete(existing, name)
+			continue
+		}
+		song, err := CreateSong(name)
+		if err != nil {
+			for _, song := range created {
+				song.EndSong()
+			}
+			return fmt.Errorf("Could not update station songs. Song %s brought error: %v", name, err)
+		}
+		songs = append(songs, song)
+		created = append(created, song)
+	}
+	s.songsMutex.Lock()
+	var playing *Song
+	if len(s.songs) > 0 {
+		playing = s.songs[s.currentSong]
+	}
+	s.songs = songs
+	s.numSongs.Store(uint64(len(songs)))
+	s.currentSong = 0
+	s.replaced = len(songs) > 0
+	for idx, song := range songs {
+		if song == playing {
+			s.currentSong = idx
+			s.replaced = false
+		}
+	}
+	s.forgetSongs()
+	s.songsMutex.Unlock()
+	// closing a removed song ends it if it was playing
+	for _, song := range existing {
+		song.EndSong()
+	}
+	return nil
+}
+
+// GetPosition returns the index and name of the current song and how far into it the station is
+func (s *Station) GetPosition() (int, string, int64) {
+	s.songsMutex.RLock()
+	defer s.songsMutex.RUnlock()
+	if len(s.songs) == 0 {
+		return 0, "", 0
+	}
+	song := s.songs[s.currentSong]
+	return s.currentSong, song.GetSongName(), song.GetPosition()
+}
+
+// RestorePosition moves the station to a song and offset. The song is found by name, or by index if the name is gone
+func (s *Station) RestorePosition(songIdx int, songName string, offset int64) error {
+	s.songsMutex.Lock()
+	defer s.songsMutex.Unlock()
+	idx := -1
+	for i, song := range s.songs {
+		if song.GetSongName() == songName {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 && songIdx >= 0 && songIdx < len(s.songs) {
+		idx = songIdx
+		offset = 0
+	}
+	if idx < 0 {
+		return fmt.Errorf("Song %s is not on the station", songName)
+	}
+	err := s.songs[idx].SeekTo(offset)
+	if err != nil {
+		return err
+	}
+	s.currentSong = idx
+	s.forgetSongs()
+	return nil
+}
+
 // GetStationSongs gets a list of all the songs on the station
 func (s *Station) GetStationSongs() []string {
 	songs := make([]string, 0)
@@ -89,11 +593,28 @@ func (s *Station) GetStationSongs() []string {
 
 // quitStation closes all the songs and exits the station
 func (s *Station) quitStation() {
+	s.doneOnce.Do(func() {
+		close(s.doneChan)
+	})
 	s.songsMutex.RLock()
 	for i := 0; i < len(s.songs); i++ {
 		s.songs[i].EndSong()
 	}
+	if s.pending != nil {
+		for _, song := range s.pending.songs {
+			song.EndSong()
+		}
+	}
+	for _, clip := range s.clips {
+		clip.EndSong()
+	}
+	if s.fallback != nil {
+		s.fallback.EndSong()
+	}
 	s.songsMutex.RUnlock()
+	if live := s.getLive(); live != nil {
+		live.Quit()
+	}
 	// unsubscribe all clients
 	for addr := range s.subscribers {
 		s.subscriberMutex.RLock()
@@ -114,11 +635,26 @@ func (s *Station) GetSubscribers() []string {
 	return subscribers
 }
 
-// subscribe subscribes a client to the station
-func (s *Station) subscribe(connAddr net.Addr, subscriber *Subscriber) {
+// GetNumListeners returns how many clients are listening to the station
+func (s *Station) GetNumListeners() int {
+	s.subscriberMutex.RLock()
+	defer s.subscriberMutex.RUnlock()
+	return len(s.subscribers)
+}
+
+// subscribe subscribes a client to the station. Errors if the station is full or shutting down
+func (s *Station) subscribe(connAddr net.Addr, subscriber *Subscriber) error {
 	s.subscriberMutex.Lock()
 	defer s.subscriberMutex.Unlock()
+	if s.draining.Load() {
+		return fmt.Errorf("station is shutting down")
+	}
+	maxListeners := int(s.maxListeners.Load())
+	if _, ok := s.subscribers[connAddr]; !ok && maxListeners > 0 && len(s.subscribers) >= maxListeners {
+		return fmt.Errorf("station is full with %d listeners", maxListeners)
+	}
 	s.subscribers[connAddr] = subscriber
+	return nil
 }
 
 // unsubscribe unsubscribes a client from the station
@@ -143,48 +679,314 @@ func (s *Station) publishData(data *SongData) {
 	s.subscriberMutex.RUnlock()
 }
 
-// publishChange publishes the name of the new song to all subscribers
+// sendLatest sends a value on a subscriber channel without waiting. A value the subscriber hasn't picked up yet is
+// replaced, since subscribers only need the latest one
+func sendLatest(channel interface{}, value interface{}) {
+	ch, v := reflect.ValueOf(channel), reflect.ValueOf(value)
+	if ch.TrySend(v) {
+		return
+	}
+	ch.TryRecv()
+	ch.TrySend(v)
+}
+
+// publishInterstitial publishes the name of a clip played between songs to all subscribers. Subscribers only need
+// the latest clip
+func (s *Station) publishInterstitial(clip string) {
+	s.subscriberMutex.RLock()
+	defer s.subscriberMutex.RUnlock()
+	for _, subChan := range s.subscribers {
+		sendLatest(subChan.PlayInterstitial, clip)
+	}
+}
+
+// publishPause tells all subscribers the station paused or resumed. Subscribers only need the latest state
+func (s *Station) publishPause(paused bool) {
+	s.subscriberMutex.RLock()
+	defer s.subscriberMutex.RUnlock()
+	for _, subChan := range s.subscribers {
+		sendLatest(subChan.PauseChange, paused)
+	}
+}
+
+// publishChange publishes the name of the new song to all subscribers and watchers
 func (s *Station) publishChange(song string) {
 	s.subscriberMutex.RLock()
 	for _, subChan := range s.subscribers {
 		subChan.ChangeSong <- song
 	}
+	s.publishWatchers(song)
 	s.subscriberMutex.RUnlock()
 }
 
 // StartStation cycles through all songs on the station, playing them.
 func (s *Station) StartStation() {
-	songIdx := 0
+	s.startDirectoryWatch()
+	go s.watchSchedule()
+	go s.watchHealth()
+	// chunks of the current song played so far, to tell an empty song from one that finished
+	chunks := 0
 	for {
 		select {
 		case <-s.quitChan:
 			s.quitStation()
 			return
 		default:
+			if s.isDrained() {
+				// the station plays nothing more while it waits to be removed
+				time.Sleep(s.sleepTime.Load())
+				continue
+			}
+			if s.paused.Load() {
+				if s.draining.Load() {
+					s.finishDrain()
+				}
+				s.drainLive()
+				time.Sleep(s.sleepTime.Load())
+				continue
+			}
+			if s.playLive() {
+				continue
+			}
 			s.songsMutex.RLock()
-			data, err := s.songs[songIdx].GetSongDataChunk()
+			if len(s.songs) == 0 || s.stopped {
+				showStarting := s.pending != nil
+				s.songsMutex.RUnlock()
+				if s.draining.Load() {
+					s.finishDrain()
+					continue
+				}
+				if showStarting {
+					s.nextSong(nil)
+					continue
+				}
+				// nothing to fall back on so the station stays silent until the feed returns, the mode changes or a show starts
+				time.Sleep(s.sleepTime.Load())
+				continue
+			}
+			song := s.songs[s.currentSong]
+			isClip := s.clip != nil
+			if isClip {
+				song = s.clip
+			}
+			skipped := s.skipping == song
+			replaced := s.replaced && !isClip
+			silent := s.silent
 			s.songsMutex.RUnlock()
+			if replaced {
+				// the song that took the place of the current one is announced like any other next song
+				chunks = 0
+				s.nextSong(nil)
+				continue
+			}
+			if silent {
+				if s.draining.Load() {
+					// there is no song to wait for
+					s.finishDrain()
+					continue
+				}
+				s.playFiller()
+				// songs may have been fixed or added in the meantime
+				s.leaveSilence()
+				continue
+			}
+			if skipped {
+				song.ResetSong()
+				chunks = 0
+				s.nextSong(song)
+				continue
+			}
+			data, err := song.GetSongDataChunk()
 
 			if err == nil {
+				chunks++
 				// sleep before sending out the rest of the message
-				time.Sleep(utils.SLEEPTIME * time.Millisecond)
+				time.Sleep(s.sleepTime.Load())
 				s.publishData(data)
 			} else {
-				s.songsMutex.RLock()
-				s.songs[songIdx].ResetSong()
-				s.songsMutex.RUnlock()
-				songIdx = (songIdx + 1) % int(s.numSongs.Load())
-				s.currentSong = songIdx
-				// publishing song change
-				s.songsMutex.RLock()
-				s.publishChange(s.songs[songIdx].GetSongName())
-				s.songsMutex.RUnlock()
+				if !isClip && err != io.EOF {
+					s.markUnplayable(song, fmt.Sprintf("Could not read it. Error: %v", err))
+				} else if !isClip && chunks == 0 {
+					s.markUnplayable(song, "It has nothing to play")
+				}
+				song.ResetSong()
+				chunks = 0
+				s.nextSong(song)
+			}
+		}
+	}
+}
+
+// nextSong moves the station on from the song or clip that just finished and announces what plays next.
+// A show that is due starts here so it never cuts a song off, and a clip that is due plays before the next song
+func (s *Station) nextSong(finished *Song) {
+	if s.draining.Load() {
+		// the last song of a draining station is over
+		s.finishDrain()
+		return
+	}
+	s.songsMutex.Lock()
+	var ended []*Song
+	s.skipping = nil
+	s.replaced = false
+	// the song after a clip was picked before the clip started
+	clipEnded := s.clip != nil && s.clip == finished
+	if clipEnded {
+		s.clip = nil
+	} else if finished != nil {
+		s.songsSinceClip++
+	}
+	if s.pending != nil {
+		ended = s.startShow()
+	} else if !clipEnded && len(s.songs) > 0 && s.songs[s.currentSong] == finished {
+		// the playlist may have changed under the song, in which case the current song is already the next one
+		var next *Song
+		if request := s.popRequest(); request != nil {
+			// requests play ahead of the planned songs
+			next = request.song
+			s.requested = request
+			s.remember(next)
+		} else {
+			next = s.popUpcoming()
+		}
+		// songs that can't be played are passed over
+		for tries := 0; next != nil && s.isUnplayable(next) && tries < len(s.songs); tries++ {
+			next = s.popUpcoming()
+		}
+		if next != nil && s.isUnplayable(next) {
+			next = s.nextPlayable()
+			if next == nil {
+				// keep the place so the station picks up from here once a song works again
+				next = s.songs[s.currentSong]
+				s.silent = true
+				s.warn(fmt.Sprintf("station %s: no songs can be played. Playing %s", s.name, s.fillerTitle()))
+			}
+		}
+		if next == nil {
+			// nothing left to play once through
+			s.stopped = true
+		} else {
+			s.setCurrent(next)
+		}
+	}
+	if len(s.songs) == 0 || s.stopped {
+		s.songsMutex.Unlock()
+		for _, song := range ended {
+			song.EndSong()
+		}
+		return
+	}
+	if !clipEnded {
+		if clip := s.dueInterstitial(); clip != nil {
+			s.clip = clip
+			s.songsMutex.Unlock()
+			for _, song := range ended {
+				song.EndSong()
+			}
+			s.publishInterstitial(clip.GetSongName())
+			return
+		}
+	}
+	songName := s.songs[s.currentSong].GetSongName()
+	if s.silent {
+		songName = s.fillerTitle()
+	}
+	requested := s.requested
+	s.requested = nil
+	s.songsMutex.Unlock()
+	for _, song := range ended {
+		song.EndSong()
+	}
+	// publishing song change
+	s.publishChange(songName)
+	if requested != nil && requested.onStart != nil {
+		go requested.onStart()
+	}
+}
+
+// playLive publishes the next chunk of the live feed. It returns false when the station should play its playlist instead
+func (s *Station) playLive() bool {
+	live := s.getLive()
+	if live == nil {
+		return false
+	}
+	if !s.onAir.Load() {
+		// only check in on the feed between chunks of the playlist
+		select {
+		case data, ok := <-live.dataChan:
+			if !ok {
+				s.endLive(live)
+				return false
+			}
+			s.onAir.Store(true)
+			s.lastLiveData = time.Now()
+			s.publishChange(live.GetTitle())
+			s.publishData(data)
+			return true
+		default:
+			return false
+		}
+	}
+	select {
+	case data, ok := <-live.dataChan:
+		if !ok {
+			s.endLive(live)
+			return false
+		}
+		s.lastLiveData = time.Now()
+		s.publishData(data)
+	case <-time.After(s.sleepTime.Load()):
+		if time.Since(s.lastLiveData) >= utils.LIVETIMEOUT*time.Millisecond {
+			s.goOffAir()
+			return false
+		}
+	}
+	return true
+}
 
+// drainLive throws away live data that arrives while the station is paused so the feed is current when it resumes
+func (s *Station) drainLive() {
+	live := s.getLive()
+	if live == nil {
+		return
+	}
+	for {
+		select {
+		case _, ok := <-live.dataChan:
+			if !ok {
+				s.endLive(live)
+				return
 			}
+		default:
+			return
 		}
 	}
 }
 
+// endLive detaches a live feed that has ended for good
+func (s *Station) endLive(live *LiveSource) {
+	s.liveMutex.Lock()
+	if s.live == live {
+		s.live = nil
+	}
+	s.liveMutex.Unlock()
+	live.Quit()
+	s.goOffAir()
+}
+
+// goOffAir takes the live feed off air and announces the playlist song the station falls back to
+func (s *Station) goOffAir() {
+	if !s.onAir.Swap(false) {
+		return
+	}
+	if s.numSongs.Load() == 0 {
+		return
+	}
+	s.songsMutex.RLock()
+	s.publishChange(s.songs[s.currentSong].GetSongName())
+	s.songsMutex.RUnlock()
+}
+
 // Quit quits the station
 func (s *Station) Quit() {
 	s.quitChan <- struct{}{}
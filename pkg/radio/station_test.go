@@ -1,10 +1,14 @@
 package radio
 
 import (
+	"io/ioutil"
 	"net"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/IMaloney/snowcast/pkg/config"
 	"github.com/IMaloney/snowcast/pkg/utils"
 )
 
@@ -289,3 +293,374 @@ func TestStartStation(t *testing.T) {
 		t.Errorf("expected: 0, received: %d", len(station.subscribers))
 	}
 }
+
+func TestCreateStationFromConfig(t *testing.T) {
+	_, err := CreateStationFromConfig(config.StationConfig{Name: "bad", Songs: []string{"poop"}})
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	station, err := CreateStationFromConfig(config.StationConfig{
+		Name:         "focus",
+		Songs:        []string{"../../mp3/tinyfile"},
+		SleepTime:    10,
+		MaxListeners: 1,
+	})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer station.quitStation()
+	if station.GetName() != "focus" {
+		t.Errorf("expected: focus, received: %s", station.GetName())
+	}
+	if station.sleepTime.Load() != 10*time.Millisecond {
+		t.Errorf("expected: %v, received: %v", 10*time.Millisecond, station.sleepTime.Load())
+	}
+	udpAddr, err := net.ResolveUDPAddr("udp", ":4444")
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	first, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	defer first.Close()
+	second, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	defer second.Close()
+	if err := station.subscribe(first.LocalAddr(), CreateSubscriber(first)); err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	if err := station.subscribe(second.LocalAddr(), CreateSubscriber(second)); err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+}
+
+func TestSetSongs(t *testing.T) {
+	song := "../../mp3/tinyfile"
+	song2 := "../../mp3/mediumfile"
+	song3 := "../../mp3/FX-Impact193.mp3"
+	station, err := CreateStation([]string{song, song2})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer station.quitStation()
+	station.currentSong = 1
+	err = station.SetSongs([]string{song3, "poop"})
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	err = station.SetSongs([]string{song3, song2})
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	songs := station.GetStationSongs()
+	if len(songs) != 2 || songs[0] != song3 || songs[1] != song2 {
+		t.Errorf("expected: [%s %s], received: %v", song3, song2, songs)
+	}
+	// the playing song keeps playing after the update
+	if station.GetCurrentSong() != song2 {
+		t.Errorf("expected: %s, received: %s", song2, station.GetCurrentSong())
+	}
+	if station.numSongs.Load() != 2 {
+		t.Errorf("expected: 2, received: %d", station.numSongs.Load())
+	}
+}
+
+func TestRemoveSong(t *testing.T) {
+	song := "../../mp3/tinyfile"
+	song2 := "../../mp3/mediumfile"
+	song3 := "../../mp3/FX-Impact193.mp3"
+	station, err := CreateStation([]string{song, song2, song3})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer station.quitStation()
+	station.currentSong = 2
+	if err := station.RemoveSong("poop"); err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	if err := station.RemoveSong(song); err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	if station.GetCurrentSong() != song3 {
+		t.Errorf("expected: %s, received: %s", song3, station.GetCurrentSong())
+	}
+	// removing the playing song moves on to the next one
+	if err := station.RemoveSong(song3); err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	if station.GetCurrentSong() != song2 {
+		t.Errorf("expected: %s, received: %s", song2, station.GetCurrentSong())
+	}
+	if station.numSongs.Load() != 1 {
+		t.Errorf("expected: 1, received: %d", station.numSongs.Load())
+	}
+}
+
+func TestRescanDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snowcast")
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	first := filepath.Join(dir, "a.mp3")
+	ioutil.WriteFile(first, []byte("a"), 0644)
+	station, err := CreateStationFromConfig(config.StationConfig{Name: "dir", Directory: dir})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer station.quitStation()
+	second := filepath.Join(dir, "b.mp3")
+	ioutil.WriteFile(second, []byte("b"), 0644)
+	os.Remove(first)
+	station.rescanDirectory()
+	songs := station.GetStationSongs()
+	if len(songs) != 1 || songs[0] != second {
+		t.Errorf("expected: [%s], received: %v", second, songs)
+	}
+}
+
+func TestStartDirectoryWatch(t *testing.T) {
+	station, err := CreateStation([]string{"../../mp3/tinyfile"})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer station.quitStation()
+	// stations without a directory don't rescan
+	station.startDirectoryWatch()
+	if station.dirWatched.Load() {
+		t.Errorf("expected: false, received: true")
+	}
+	station.directory.Store("../../mp3")
+	station.startDirectoryWatch()
+	if !station.dirWatched.Load() {
+		t.Errorf("expected: true, received: false")
+	}
+}
+
+func TestMoveSong(t *testing.T) {
+	song := "../../mp3/tinyfile"
+	song2 := "../../mp3/mediumfile"
+	song3 := "../../mp3/FX-Impact193.mp3"
+	station, err := CreateStation([]string{song, song2, song3})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer station.quitStation()
+	if station.MoveSong(0, 3) == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	err = station.MoveSong(0, 2)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	songs := station.GetStationSongs()
+	expected := []string{song2, song3, song}
+	for idx := range expected {
+		if songs[idx] != expected[idx] {
+			t.Errorf("expected: %s, received: %s", expected[idx], songs[idx])
+		}
+	}
+	// the song that was playing keeps playing
+	if station.GetCurrentSong() != song {
+		t.Errorf("expected: %s, received: %s", song, station.GetCurrentSong())
+	}
+	if upcoming := station.GetUpcomingSongs(1); upcoming[0] != song2 {
+		t.Errorf("expected: %s, received: %s", song2, upcoming[0])
+	}
+}
+
+func TestSkip(t *testing.T) {
+	udpAddr, err := net.ResolveUDPAddr("udp", ":4447")
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	udpConn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	defer udpConn.Close()
+	subscriber := CreateSubscriber(udpConn)
+
+	song := "../../mp3/FX-Impact193.mp3"
+	song2 := "../../mp3/mediumfile"
+	station, err := CreateStation([]string{song, song2})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	station.subscribe(udpConn.RemoteAddr(), subscriber)
+	go station.StartStation()
+	defer station.Quit()
+	err = station.Skip()
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	select {
+	case name := <-subscriber.ChangeSong:
+		if name != song2 {
+			t.Errorf("expected: %s, received: %s", song2, name)
+		}
+	case <-time.After(3 * time.Second):
+		t.Errorf("expected: %s, received: nothing", song2)
+	}
+}
+
+func TestReplaceCurrentSong(t *testing.T) {
+	udpAddr, err := net.ResolveUDPAddr("udp", ":4451")
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	udpConn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	defer udpConn.Close()
+	subscriber := CreateSubscriber(udpConn)
+
+	song := "../../mp3/tinyfile"
+	song2 := "../../mp3/mediumfile"
+	song3 := "../../mp3/FX-Impact193.mp3"
+	station, err := CreateStation([]string{song, song2, song3})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	station.currentSong = 1
+	station.subscribe(udpConn.RemoteAddr(), subscriber)
+	go station.StartStation()
+	defer station.Quit()
+	// removing the playing song announces the song that takes its place
+	err = station.RemoveSong(song2)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	select {
+	case name := <-subscriber.ChangeSong:
+		if name != song3 {
+			t.Errorf("expected: %s, received: %s", song3, name)
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("expected: %s, received: nothing", song3)
+	}
+	// so does reloading it away
+	err = station.SetSongs([]string{song})
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	select {
+	case name := <-subscriber.ChangeSong:
+		if name != song {
+			t.Errorf("expected: %s, received: %s", song, name)
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("expected: %s, received: nothing", song)
+	}
+}
+
+func TestPauseStation(t *testing.T) {
+	udpAddr, err := net.ResolveUDPAddr("udp", ":5556")
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	listener, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	defer listener.Close()
+	udpConn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	defer udpConn.Close()
+	subscriber := CreateSubscriber(udpConn)
+
+	song := "../../mp3/FX-Impact193.mp3"
+	station, err := CreateStation([]string{song})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	station.sleepTime.Store(10 * time.Millisecond)
+	station.subscribe(udpConn.RemoteAddr(), subscriber)
+	err = station.Pause()
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	if paused := <-subscriber.PauseChange; !paused {
+		t.Errorf("expected: true, received: false")
+	}
+	if station.Pause() == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	go station.StartStation()
+	defer station.Quit()
+	buffer := make([]byte, utils.SONGCHUNK)
+	listener.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+	if _, err := listener.Read(buffer); err == nil {
+		t.Errorf("expected: no audio while paused, received: audio")
+	}
+	// the station keeps its place and its listeners
+	if station.songs[0].GetPosition() != 0 || len(station.GetSubscribers()) != 1 {
+		t.Errorf("expected: position 0 with 1 listener, received: %d with %d", station.songs[0].GetPosition(), len(station.GetSubscribers()))
+	}
+	err = station.Resume()
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	if paused := <-subscriber.PauseChange; paused {
+		t.Errorf("expected: false, received: true")
+	}
+	listener.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := listener.Read(buffer); err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	// a subscriber that falls behind only gets the latest state
+	station.Pause()
+	station.Resume()
+	if paused := <-subscriber.PauseChange; paused {
+		t.Errorf("expected: false, received: true")
+	}
+}
+
+func TestDrain(t *testing.T) {
+	udpAddr, err := net.ResolveUDPAddr("udp", ":4448")
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	udpConn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	defer udpConn.Close()
+
+	song := "../../mp3/mediumfile"
+	station, err := CreateStation([]string{song})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	defer station.quitStation()
+	done, timeLeft, err := station.Drain()
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	// 22742 bytes is 89 chunks a second apart
+	if timeLeft != 89*time.Second {
+		t.Errorf("expected: %v, received: %v", 89*time.Second, timeLeft)
+	}
+	if _, _, err := station.Drain(); err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	err = station.subscribe(udpConn.RemoteAddr(), CreateSubscriber(udpConn))
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	if station.isDrained() {
+		t.Errorf("expected: false, received: true")
+	}
+	station.nextSong(station.songs[station.currentSong])
+	select {
+	case <-done:
+	default:
+		t.Errorf("expected: drained, received: still draining")
+	}
+}
@@ -0,0 +1,87 @@
+package radio
+
+import (
+	"fmt"
+	"math"
+	"net"
+
+	"github.com/IMaloney/snowcast/pkg/utils"
+)
+
+// SkipVotes is the tally of votes to skip the song playing on a station
+type SkipVotes struct {
+	Votes  int
+	Needed int
+}
+
+// SetSkipFraction sets the fraction of listeners that have to vote to skip a song. 0 uses the default fraction
+func (s *Station) SetSkipFraction(fraction float64) {
+	if fraction <= 0 {
+		fraction = utils.SKIPFRACTION
+	}
+	s.songsMutex.Lock()
+	s.skipFraction = fraction
+	s.songsMutex.Unlock()
+}
+
+// VoteSkip counts a listener's vote to skip the song playing. The song is skipped once enough of the listeners
+// voted for it. Every listener is sent the new tally
+func (s *Station) VoteSkip(connAddr net.Addr) (SkipVotes, error) {
+	s.subscriberMutex.RLock()
+	listeners := make(map[string]bool)
+	for addr := range s.subscribers {
+		listeners[addr.String()] = true
+	}
+	s.subscriberMutex.RUnlock()
+	if !listeners[connAddr.String()] {
+		return SkipVotes{}, fmt.Errorf("Only listeners of the station can vote to skip")
+	}
+	if s.onAir.Load() {
+		return SkipVotes{}, fmt.Errorf("A live feed can't be skipped")
+	}
+	s.songsMutex.Lock()
+	var target *Song
+	switch {
+	case s.clip != nil:
+		target = s.clip
+	case len(s.songs) == 0 || s.stopped || s.silent:
+		s.songsMutex.Unlock()
+		return SkipVotes{}, fmt.Errorf("Nothing is playing on the station")
+	default:
+		target = s.songs[s.currentSong]
+	}
+	// votes only count for the song they were cast against
+	if target != s.voteSong {
+		s.votes = make(map[string]bool)
+		s.voteSong = target
+	}
+	s.votes[connAddr.String()] = true
+	tally := SkipVotes{
+		Needed: int(math.Ceil(s.skipFraction * float64(len(listeners)))),
+	}
+	if tally.Needed < 1 {
+		tally.Needed = 1
+	}
+	for voter := range s.votes {
+		// listeners that left don't count
+		if listeners[voter] {
+			tally.Votes++
+		}
+	}
+	if tally.Votes >= tally.Needed {
+		s.skipping = target
+		s.votes = make(map[string]bool)
+	}
+	s.songsMutex.Unlock()
+	s.publishVotes(tally)
+	return tally, nil
+}
+
+// publishVotes publishes the skip vote tally to all subscribers. Subscribers only need the latest tally
+func (s *Station) publishVotes(tally SkipVotes) {
+	s.subscriberMutex.RLock()
+	defer s.subscriberMutex.RUnlock()
+	for _, subChan := range s.subscribers {
+		sendLatest(subChan.SkipVotes, tally)
+	}
+}
@@ -0,0 +1,78 @@
+package radio
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestVoteSkip(t *testing.T) {
+	udpAddr, err := net.ResolveUDPAddr("udp", ":4449")
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	udpConn, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	defer udpConn.Close()
+	udpConn2, err := net.DialUDP("udp", nil, udpAddr)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	defer udpConn2.Close()
+	subscriber := CreateSubscriber(udpConn)
+	subscriber2 := CreateSubscriber(udpConn2)
+
+	song1 := "../../mp3/FX-Impact193.mp3"
+	song2 := "../../mp3/mediumfile"
+	station, err := CreateStation([]string{song1, song2})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	station.SetSkipFraction(1)
+	station.subscribe(udpConn.LocalAddr(), subscriber)
+	station.subscribe(udpConn2.LocalAddr(), subscriber2)
+	go station.StartStation()
+	defer station.Quit()
+
+	_, err = station.VoteSkip(udpAddr)
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	tally, err := station.VoteSkip(udpConn.LocalAddr())
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	if tally.Votes != 1 || tally.Needed != 2 {
+		t.Errorf("expected: 1 of 2 votes, received: %d of %d", tally.Votes, tally.Needed)
+	}
+	// voting twice doesn't count twice
+	tally, _ = station.VoteSkip(udpConn.LocalAddr())
+	if tally.Votes != 1 {
+		t.Errorf("expected: 1, received: %d", tally.Votes)
+	}
+	select {
+	case received := <-subscriber2.SkipVotes:
+		if received != tally {
+			t.Errorf("expected: %v, received: %v", tally, received)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("expected: %v, received: nothing", tally)
+	}
+	tally, err = station.VoteSkip(udpConn2.LocalAddr())
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	if tally.Votes != 2 {
+		t.Errorf("expected: 2, received: %d", tally.Votes)
+	}
+	select {
+	case name := <-subscriber.ChangeSong:
+		if name != song2 {
+			t.Errorf("expected: %s, received: %s", song2, name)
+		}
+	case <-time.After(3 * time.Second):
+		t.Errorf("expected: %s, received: nothing", song2)
+	}
+}
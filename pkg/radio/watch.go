@@ -0,0 +1,84 @@
+package radio
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/IMaloney/snowcast/pkg/utils"
+)
+
+// WatchedSong is a song that started on a watched station
+type WatchedSong struct {
+	Station uint16
+	Song    string
+}
+
+// Watcher follows the song changes of any number of stations without listening to them
+type Watcher struct {
+	Changes chan WatchedSong
+}
+
+// CreateWatcher creates a watcher
+func CreateWatcher() *Watcher {
+	return &Watcher{
+		Changes: make(chan WatchedSong, utils.WATCHBUFFER),
+	}
+}
+
+// stationWatch is a watcher of a station and the number the station is watched as
+type stationWatch struct {
+	stationNum uint16
+	watcher    *Watcher
+}
+
+// watch starts sending the song changes of the station to a watcher. Watchers aren't listeners so full and
+// draining stations can still be watched
+func (s *Station) watch(connAddr net.Addr, stationNum uint16, watcher *Watcher) {
+	s.subscriberMutex.Lock()
+	defer s.subscriberMutex.Unlock()
+	s.watchers[connAddr] = stationWatch{stationNum: stationNum, watcher: watcher}
+}
+
+// unwatch stops sending the song changes of the station to a watcher
+func (s *Station) unwatch(connAddr net.Addr) error {
+	s.subscriberMutex.Lock()
+	defer s.subscriberMutex.Unlock()
+	if _, ok := s.watchers[connAddr]; !ok {
+		return fmt.Errorf("%s is not watching the station", connAddr.String())
+	}
+	delete(s.watchers, connAddr)
+	return nil
+}
+
+// publishWatchers publishes the name of the new song to all watchers. A watcher that fell behind misses the change
+// rather than holding up the station. subscriberMutex must be held
+func (s *Station) publishWatchers(song string) {
+	for _, watch := range s.watchers {
+		select {
+		case watch.watcher.Changes <- WatchedSong{Station: watch.stationNum, Song: song}:
+		default:
+		}
+	}
+}
+
+// WatchStation sends the song changes of a station to a watcher and returns the song playing on it
+func (r *Radio) WatchStation(stationNum uint16, conn net.Addr, watcher *Watcher) (string, error) {
+	if !r.stationExists(stationNum) {
+		return "", fmt.Errorf("Station %d does not exist", stationNum)
+	}
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	station := r.stationMap[stationNum]
+	station.watch(conn, stationNum, watcher)
+	return station.GetCurrentSong(), nil
+}
+
+// UnwatchStation stops sending the song changes of a station to a watcher
+func (r *Radio) UnwatchStation(stationNum uint16, conn net.Addr) error {
+	if !r.stationExists(stationNum) {
+		return fmt.Errorf("Station %d does not exist", stationNum)
+	}
+	r.stationMapMutex.RLock()
+	defer r.stationMapMutex.RUnlock()
+	return r.stationMap[stationNum].unwatch(conn)
+}
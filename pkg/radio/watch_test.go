@@ -0,0 +1,54 @@
+package radio
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestWatchStation(t *testing.T) {
+	addr, err := net.ResolveUDPAddr("udp", ":4450")
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	watcher := CreateWatcher()
+
+	song1 := "../../mp3/FX-Impact193.mp3"
+	song2 := "../../mp3/mediumfile"
+	station, err := CreateStation([]string{song1, song2})
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	station.watch(addr, 7, watcher)
+	go station.StartStation()
+	defer station.Quit()
+
+	// watching doesn't make the watcher a listener
+	if station.GetNumListeners() != 0 {
+		t.Errorf("expected: 0, received: %d", station.GetNumListeners())
+	}
+	time.Sleep(100 * time.Millisecond)
+	station.Skip()
+	select {
+	case change := <-watcher.Changes:
+		if change.Station != 7 || change.Song != song2 {
+			t.Errorf("expected: %s on station 7, received: %s on station %d", song2, change.Song, change.Station)
+		}
+	case <-time.After(3 * time.Second):
+		t.Errorf("expected: %s, received: nothing", song2)
+	}
+	err = station.unwatch(addr)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	err = station.unwatch(addr)
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+	station.Skip()
+	select {
+	case change := <-watcher.Changes:
+		t.Errorf("expected: nothing, received: %s", change.Song)
+	case <-time.After(1500 * time.Millisecond):
+	}
+}
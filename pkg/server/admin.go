@@ -0,0 +1,231 @@
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// RunCommand runs a station management command from the console or an admin client and returns what it did
+func (s *Server) RunCommand(vals []string) (string, error) {
+	if len(vals) == 0 {
+		return "", fmt.Errorf("no command given")
+	}
+	switch vals[0] {
+	case "addSong", "as":
+		if len(vals) < 3 {
+			return "", fmt.Errorf("usage: addSong [stationNumber] [song]")
+		}
+		stationNum, err := parseStation(vals[1])
+		if err != nil {
+			return "", err
+		}
+		// the song is the rest of the line so paths can have spaces
+		song := strings.Join(vals[2:], " ")
+		err = s.radio.AddSong(stationNum, song)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("added %s to station %d", song, stationNum), nil
+	case "removeSong", "rs":
+		if len(vals) < 3 {
+			return "", fmt.Errorf("usage: removeSong [stationNumber] [song]")
+		}
+		stationNum, err := parseStation(vals[1])
+		if err != nil {
+			return "", err
+		}
+		// the song is the rest of the line so paths can have spaces
+		song := strings.Join(vals[2:], " ")
+		err = s.radio.RemoveSong(stationNum, song)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("removed %s from station %d", song, stationNum), nil
+	case "moveSong", "ms":
+		if len(vals) != 4 {
+			return "", fmt.Errorf("usage: moveSong [stationNumber] [from] [to]")
+		}
+		stationNum, err := parseStation(vals[1])
+		if err != nil {
+			return "", err
+		}
+		from, err := strconv.Atoi(vals[2])
+		if err != nil {
+			return "", fmt.Errorf("could not recognize number %s", vals[2])
+		}
+		to, err := strconv.Atoi(vals[3])
+		if err != nil {
+			return "", fmt.Errorf("could not recognize number %s", vals[3])
+		}
+		err = s.radio.MoveSong(stationNum, from, to)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("moved song %d to %d on station %d", from, to, stationNum), nil
+	case "skip", "s":
+		if len(vals) != 2 {
+			return "", fmt.Errorf("usage: skip [stationNumber]")
+		}
+		stationNum, err := parseStation(vals[1])
+		if err != nil {
+			return "", err
+		}
+		err = s.radio.SkipSong(stationNum)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("skipped the song playing on station %d", stationNum), nil
+	case "pause":
+		if len(vals) != 2 {
+			return "", fmt.Errorf("usage: pause [stationNumber]")
+		}
+		stationNum, err := parseStation(vals[1])
+		if err != nil {
+			return "", err
+		}
+		err = s.radio.PauseStation(stationNum)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("paused station %d", stationNum), nil
+	case "resume":
+		if len(vals) != 2 {
+			return "", fmt.Errorf("usage: resume [stationNumber]")
+		}
+		stationNum, err := parseStation(vals[1])
+		if err != nil {
+			return "", err
+		}
+		err = s.radio.ResumeStation(stationNum)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("resumed station %d", stationNum), nil
+	case "migrate", "mv":
+		if len(vals) != 3 {
+			return "", fmt.Errorf("usage: migrate [fromStationNumber] [toStationNumber]")
+		}
+		from, err := parseStation(vals[1])
+		if err != nil {
+			return "", err
+		}
+		to, err := parseStation(vals[2])
+		if err != nil {
+			return "", err
+		}
+		moved, failed, err := s.MigrateListeners(from, to)
+		if err != nil {
+			return "", err
+		}
+		if failed > 0 {
+			return fmt.Sprintf("moved %d listeners from station %d to station %d. %d could not join it", moved, from, to, failed), nil
+		}
+		return fmt.Sprintf("moved %d listeners from station %d to station %d", moved, from, to), nil
+	case "mute":
+		if len(vals) > 2 {
+			return "", fmt.Errorf("usage: mute [nickname]")
+		}
+		if len(vals) == 1 {
+			muted := s.GetMuted()
+			if len(muted) == 0 {
+				return "no address is muted", nil
+			}
+			return fmt.Sprintf("muted addresses: %s", strings.Join(muted, ", ")), nil
+		}
+		host, clients, err := s.Mute(vals[1])
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("muted %s, the address of %s. The mute covers every client on it, %d connected now", host, vals[1], clients), nil
+	case "unmute":
+		if len(vals) != 2 {
+			return "", fmt.Errorf("usage: unmute [nickname or address]")
+		}
+		if !s.Unmute(vals[1]) {
+			return "", fmt.Errorf("%s is not muted", vals[1])
+		}
+		return fmt.Sprintf("unmuted %s", vals[1]), nil
+	case "health":
+		if len(vals) > 2 {
+			return "", fmt.Errorf("usage: health [stationNumber]")
+		}
+		stationNums := s.radio.GetStationNumbers()
+		if len(vals) == 2 {
+			stationNum, err := parseStation(vals[1])
+			if err != nil {
+				return "", err
+			}
+			stationNums = []uint16{stationNum}
+		}
+		lines := make([]string, 0)
+		for _, stationNum := range stationNums {
+			health, unplayable, err := s.radio.GetHealth(stationNum)
+			if err != nil {
+				return "", err
+			}
+			line := fmt.Sprintf("station %d: %s", stationNum, health)
+			if len(unplayable) > 0 {
+				line += fmt.Sprintf(". Can't play %s", strings.Join(unplayable, ", "))
+			}
+			lines = append(lines, line)
+		}
+		return strings.Join(lines, "\n"), nil
+	case "drain", "d":
+		if len(vals) != 2 && len(vals) != 3 {
+			return "", fmt.Errorf("usage: drain [stationNumber] [fallbackStationNumber]")
+		}
+		stationNum, err := parseStation(vals[1])
+		if err != nil {
+			return "", err
+		}
+		var fallback *uint16
+		if len(vals) == 3 {
+			fallbackNum, err := parseStation(vals[2])
+			if err != nil {
+				return "", err
+			}
+			fallback = &fallbackNum
+		}
+		err = s.DrainStation(stationNum, fallback)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("draining station %d", stationNum), nil
+	}
+	return "", fmt.Errorf("could not recognize command %s", vals[0])
+}
+
+// parseStation parses a station number typed in a command
+func parseStation(val string) (uint16, error) {
+	num, err := strconv.ParseUint(val, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("could not recognize station number %s", val)
+	}
+	return uint16(num), nil
+}
+
+// handleAdminRequest runs an admin command for a client that knows the admin password
+func (s *Server) handleAdminRequest(connAddr net.Addr, password, command string) error {
+	adminPassword := s.adminPassword.Load()
+	if adminPassword == "" || subtle.ConstantTimeCompare([]byte(password), []byte(adminPassword)) != 1 {
+		s.connectionsMutex.RLock()
+		err := s.connections[connAddr].sendInvalidRequest("admin commands need the admin password")
+		s.connectionsMutex.RUnlock()
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("admin password rejected")
+	}
+	reply, err := s.RunCommand(strings.Fields(command))
+	if err != nil {
+		// a bad command isn't worth dropping the admin over
+		reply = fmt.Sprintf("error: %v", err)
+	}
+	s.connectionsMutex.RLock()
+	err = s.connections[connAddr].sendAdminReply(reply)
+	s.connectionsMutex.RUnlock()
+	return err
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/IMaloney/snowcast/pkg/utils"
+)
+
+// uniqueNickname returns the nickname a client chats with. Clients without one, or whose nickname is taken,
+// get one made from their session id, with a count after it if a client chose that too. connectionsMutex must be held
+func (s *Server) uniqueNickname(nickname string, numClient int) string {
+	if nickname == "" {
+		nickname = "listener"
+	} else if !s.nicknameTaken(nickname) {
+		return nickname
+	}
+	base := fmt.Sprintf("%s-%d", nickname, numClient)
+	nickname = base
+	for suffix := 2; s.nicknameTaken(nickname); suffix++ {
+		nickname = fmt.Sprintf("%s-%d", base, suffix)
+	}
+	return nickname
+}
+
+// nicknameTaken returns true if a connected client chats with the nickname. connectionsMutex must be held
+func (s *Server) nicknameTaken(nickname string) bool {
+	for _, conn := range s.connections {
+		if conn.nickname == nickname {
+			return true
+		}
+	}
+	return false
+}
+
+// handleChatRequest relays a line of chat to every extra credit listener of the client's station. Clients have to
+// be listening, can chat once every CHATTIME milliseconds and can't chat while muted
+func (s *Server) handleChatRequest(connAddr net.Addr, text string) error {
+	s.connectionsMutex.RLock()
+	conn := s.connections[connAddr]
+	s.connectionsMutex.RUnlock()
+	text = strings.TrimSpace(text)
+	switch {
+	case !conn.isListening():
+		return conn.sendChatDenied("Tune in to a station to chat with its listeners")
+	case text == "":
+		return conn.sendChatDenied("Chat messages can't be empty")
+	case len(text) > utils.CHATLENGTH:
+		return conn.sendChatDenied(fmt.Sprintf("Chat messages can be at most %d characters", utils.CHATLENGTH))
+	case s.isMuted(conn):
+		return conn.sendChatDenied("You are muted")
+	case time.Since(conn.lastChat) < utils.CHATTIME*time.Millisecond:
+		return conn.sendChatDenied("You are sending messages too quickly")
+	}
+	conn.lastChat = time.Now()
+	stationNum := conn.currentStation
+	s.messageChan <- fmt.Sprintf("chat on station %d from %s: %s", stationNum, conn.nickname, text)
+	s.connectionsMutex.RLock()
+	defer s.connectionsMutex.RUnlock()
+	for _, listener := range s.connections {
+		if listener.extraCredit && listener.isListening() && listener.currentStation == stationNum {
+			listener.sendChat(stationNum, conn.nickname, text)
+		}
+	}
+	return nil
+}
+
+// remoteHost returns the address a client connects from, without its port
+func remoteHost(conn *connection) string {
+	host, _, err := net.SplitHostPort(conn.tcpConn.RemoteAddr().String())
+	if err != nil {
+		return conn.tcpConn.RemoteAddr().String()
+	}
+	return host
+}
+
+// clientsOn returns how many clients are connected from an address
+func (s *Server) clientsOn(host string) int {
+	s.connectionsMutex.RLock()
+	defer s.connectionsMutex.RUnlock()
+	clients := 0
+	for _, conn := range s.connections {
+		if remoteHost(conn) == host {
+			clients++
+		}
+	}
+	return clients
+}
+
+// Mute stops the address the client with the nickname connects from chatting, so reconnecting under another name
+// doesn't lift it. Every client on the address is muted, which can be more than one behind a shared address.
+// Returns the address and how many clients are connected from it
+func (s *Server) Mute(nickname string) (string, int, error) {
+	host := ""
+	s.connectionsMutex.RLock()
+	for _, conn := range s.connections {
+		if conn.nickname == nickname {
+			host = remoteHost(conn)
+		}
+	}
+	s.connectionsMutex.RUnlock()
+	if host == "" {
+		return "", 0, fmt.Errorf("no client is called %s", nickname)
+	}
+	s.mutedMutex.Lock()
+	s.muted[host] = nickname
+	s.mutedMutex.Unlock()
+	return host, s.clientsOn(host), nil
+}
+
+// Unmute lets a muted address chat again. The address can be given by itself or by the nickname it was muted as.
+// Returns false if nothing was muted under the name
+func (s *Server) Unmute(name string) bool {
+	s.mutedMutex.Lock()
+	defer s.mutedMutex.Unlock()
+	unmuted := false
+	for host, nickname := range s.muted {
+		if host == name || nickname == name {
+			delete(s.muted, host)
+			unmuted = true
+		}
+	}
+	return unmuted
+}
+
+// GetMuted describes the muted addresses in order, with the nickname each was muted as and how many clients are
+// connected from it
+func (s *Server) GetMuted() []string {
+	s.mutedMutex.RLock()
+	hosts := make(map[string]string)
+	for host, nickname := range s.muted {
+		hosts[host] = nickname
+	}
+	s.mutedMutex.RUnlock()
+	muted := make([]string, 0)
+	for host, nickname := range hosts {
+		muted = append(muted, fmt.Sprintf("%s (muted as %s, %d clients connected)", host, nickname, s.clientsOn(host)))
+	}
+	sort.Strings(muted)
+	return muted
+}
+
+// isMuted returns true if the client connects from a muted address
+func (s *Server) isMuted(conn *connection) bool {
+	s.mutedMutex.RLock()
+	defer s.mutedMutex.RUnlock()
+	_, ok := s.muted[remoteHost(conn)]
+	return ok
+}
@@ -3,6 +3,9 @@ package server
 import (
 	"fmt"
 	"net"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/IMaloney/snowcast/pkg/radio"
 	"github.com/IMaloney/snowcast/pkg/utils"
@@ -18,10 +21,27 @@ type connection struct {
 	udpConn           *net.UDPConn
 	stopStreamingChan chan struct{}
 	subscriber        *radio.Subscriber
+	// whether the server runs with extra credit. Clients don't say what they support, so with it off every client is
+	// treated like the reference client: no votes or pause changes, and interstitials get a regular announce
+	extraCredit bool
+	// when the client last had a song request queued. Only the connection handler uses it
+	lastRequest time.Time
+	// name the client chats with, unique among the connected clients
+	nickname string
+	// when the client last chatted. Only the connection handler uses it
+	lastChat time.Time
+	// follows the song changes of the stations the client watches
+	watcher       *radio.Watcher
+	watching      map[uint16]bool
+	watchingMutex sync.Mutex
+	closedChan    chan struct{}
+	// when the client said hello, and whether it has tuned in to or watched a station since
+	helloTime time.Time
+	tunedIn   *atomic.Bool
 }
 
 // createConnection creates a connection struct
-func createConnection(tcpConn *net.TCPConn, udpConn *net.UDPConn, addr net.Addr, numClient int) *connection {
+func createConnection(tcpConn *net.TCPConn, udpConn *net.UDPConn, addr net.Addr, numClient int, extraCredit bool) *connection {
 	return &connection{
 		numClient: numClient,
 		tcpConn:   tcpConn,
@@ -31,6 +51,12 @@ func createConnection(tcpConn *net.TCPConn, udpConn *net.UDPConn, addr net.Addr,
 		listening:         atomic.NewBool(false),
 		stopStreamingChan: make(chan struct{}, 1),
 		subscriber:        radio.CreateSubscriber(udpConn),
+		extraCredit:       extraCredit,
+		watcher:           radio.CreateWatcher(),
+		watching:          make(map[uint16]bool),
+		closedChan:        make(chan struct{}),
+		helloTime:         time.Now(),
+		tunedIn:           atomic.NewBool(false),
 	}
 }
 
@@ -38,6 +64,7 @@ func createConnection(tcpConn *net.TCPConn, udpConn *net.UDPConn, addr net.Addr,
 func (c *connection) closeConnection() {
 	c.udpConn.Close()
 	c.tcpConn.Close()
+	close(c.closedChan)
 }
 
 // isListening returns whether the connection is currently streaming or not
@@ -60,7 +87,7 @@ func (c *connection) sendNewStation(stationNum, numStations uint16) error {
 
 // sendStationShutDown sends a StationShutDown message
 func (c *connection) sendStationShutDown(stationNum, numStations uint16) error {
-	message, err := utils.CreateShutdownStationMessage(c.currentStation, numStations)
+	message, err := utils.CreateShutdownStationMessage(stationNum, numStations)
 	if err != nil {
 		return err
 	}
@@ -84,6 +111,283 @@ func (c *connection) sendSongsList(songs []string) error {
 	return nil
 }
 
+// sendShowInfo sends the current and next show of a station
+func (c *connection) sendShowInfo(currentShow, nextShow string, nextShowIn uint16) error {
+	message, err := utils.CreateShowInfoMessage(currentShow, nextShow, nextShowIn)
+	if err != nil {
+		return err
+	}
+	_, err = c.tcpConn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// sendStationList sends the description of every station
+func (c *connection) sendStationList(stations []utils.StationEntry) error {
+	message, err := utils.CreateStationListMessage(stations)
+	if err != nil {
+		return err
+	}
+	_, err = c.tcpConn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// sendAllStationSongs sends the songs and the song playing of every station
+func (c *connection) sendAllStationSongs(stations []utils.StationSongs) error {
+	message, err := utils.CreateAllStationSongsMessage(stations)
+	if err != nil {
+		return err
+	}
+	_, err = c.tcpConn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// sendSearchResults sends the songs matching a search
+func (c *connection) sendSearchResults(matches []utils.SongMatch) error {
+	message, err := utils.CreateSearchResultsMessage(matches)
+	if err != nil {
+		return err
+	}
+	_, err = c.tcpConn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// sendRequestQueued tells the client their requested song is queued
+func (c *connection) sendRequestQueued(stationNum, position uint16, songName string) error {
+	message, err := utils.CreateRequestQueuedMessage(stationNum, position, songName)
+	if err != nil {
+		return err
+	}
+	_, err = c.tcpConn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// sendRequestPlaying tells the client their requested song started
+func (c *connection) sendRequestPlaying(stationNum uint16, songName string) error {
+	message, err := utils.CreateRequestPlayingMessage(stationNum, songName)
+	if err != nil {
+		return err
+	}
+	_, err = c.tcpConn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// sendRequestDenied tells the client why their song request wasn't queued
+func (c *connection) sendRequestDenied(reason string) error {
+	message, err := utils.CreateRequestDeniedMessage(reason)
+	if err != nil {
+		return err
+	}
+	_, err = c.tcpConn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// sendSkipVotes sends the votes to skip the song playing. Only extra credit clients know about votes
+func (c *connection) sendSkipVotes(tally radio.SkipVotes) error {
+	if !c.extraCredit {
+		return nil
+	}
+	message, err := utils.CreateSkipVotesMessage(c.currentStation, uint16(tally.Votes), uint16(tally.Needed))
+	if err != nil {
+		return err
+	}
+	_, err = c.tcpConn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// sendVoteDenied tells the client why their vote to skip wasn't counted
+func (c *connection) sendVoteDenied(reason string) error {
+	message, err := utils.CreateVoteDeniedMessage(reason)
+	if err != nil {
+		return err
+	}
+	_, err = c.tcpConn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// sendChat sends a line of chat from a listener of the station
+func (c *connection) sendChat(stationNum uint16, nickname, text string) error {
+	message, err := utils.CreateChatReplyMessage(stationNum, nickname, text)
+	if err != nil {
+		return err
+	}
+	_, err = c.tcpConn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// sendChatDenied tells the client why their chat message wasn't sent
+func (c *connection) sendChatDenied(reason string) error {
+	message, err := utils.CreateChatDeniedMessage(reason)
+	if err != nil {
+		return err
+	}
+	_, err = c.tcpConn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// sendWatchedSong sends the song playing on a station the client watches
+func (c *connection) sendWatchedSong(stationNum uint16, songName string) error {
+	message, err := utils.CreateWatchedSongMessage(stationNum, songName)
+	if err != nil {
+		return err
+	}
+	_, err = c.tcpConn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// sendWatching sends every station the client watches and the song playing on it
+func (c *connection) sendWatching(stations []utils.WatchedStation) error {
+	message, err := utils.CreateWatchingMessage(stations)
+	if err != nil {
+		return err
+	}
+	_, err = c.tcpConn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// sendWatchDenied tells the client why it couldn't watch or unwatch a station
+func (c *connection) sendWatchDenied(reason string) error {
+	message, err := utils.CreateWatchDeniedMessage(reason)
+	if err != nil {
+		return err
+	}
+	_, err = c.tcpConn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// sendStoppedListening tells the client it stopped listening to a station
+func (c *connection) sendStoppedListening(stationNum uint16) error {
+	message, err := utils.CreateStoppedListeningMessage(stationNum)
+	if err != nil {
+		return err
+	}
+	_, err = c.tcpConn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// sendStopDenied tells the client why it couldn't stop listening
+func (c *connection) sendStopDenied(reason string) error {
+	message, err := utils.CreateStopDeniedMessage(reason)
+	if err != nil {
+		return err
+	}
+	_, err = c.tcpConn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// sendAdminReply sends the result of an admin command
+func (c *connection) sendAdminReply(reply string) error {
+	message, err := utils.CreateAdminReplyMessage(reply)
+	if err != nil {
+		return err
+	}
+	_, err = c.tcpConn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// sendPauseChange tells an extra credit client its station paused or resumed. Other clients just stop hearing audio
+func (c *connection) sendPauseChange(paused bool) error {
+	if !c.extraCredit {
+		return nil
+	}
+	message, err := utils.CreateStationResumedMessage(c.currentStation)
+	if paused {
+		message, err = utils.CreateStationPausedMessage(c.currentStation)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = c.tcpConn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// sendStationDraining tells an extra credit client how long its station has before it shuts down
+func (c *connection) sendStationDraining(stationNum, secondsLeft uint16) error {
+	if !c.extraCredit {
+		return nil
+	}
+	message, err := utils.CreateStationDrainingMessage(stationNum, secondsLeft)
+	if err != nil {
+		return err
+	}
+	_, err = c.tcpConn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// sendServerShutdown tells an extra credit client the server is going away. A client that stopped reading can't hold
+// up the shutdown
+func (c *connection) sendServerShutdown(reason string, reconnectAfter uint16) error {
+	if !c.extraCredit {
+		return nil
+	}
+	message, err := utils.CreateServerShutdownMessage(reason, reconnectAfter)
+	if err != nil {
+		return err
+	}
+	c.tcpConn.SetWriteDeadline(time.Now().Add(utils.SLEEPTIME * time.Millisecond))
+	_, err = c.tcpConn.Write(message)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 // sendAnnounce sends a Announce message
 func (c *connection) sendAnnounce(song string) error {
 	message, err := utils.CreateAnnounceMessage(song)
@@ -126,6 +430,74 @@ func (c *connection) streamStation() {
 		case song := <-c.subscriber.ChangeSong:
 			message, _ := utils.CreateAnnounceMessage(song)
 			c.tcpConn.Write(message)
+		case paused := <-c.subscriber.PauseChange:
+			c.sendPauseChange(paused)
+		case tally := <-c.subscriber.SkipVotes:
+			c.sendSkipVotes(tally)
+		case clip := <-c.subscriber.PlayInterstitial:
+			message, _ := utils.CreateAnnounceMessage(clip)
+			if c.extraCredit {
+				message, _ = utils.CreateInterstitialMessage(clip)
+			}
+			c.tcpConn.Write(message)
+		}
+	}
+}
+
+// startWatching records that the client watches a station. Returns false if it already did
+func (c *connection) startWatching(stationNum uint16) bool {
+	c.watchingMutex.Lock()
+	defer c.watchingMutex.Unlock()
+	if c.watching[stationNum] {
+		return false
+	}
+	c.watching[stationNum] = true
+	return true
+}
+
+// stopWatching records that the client no longer watches a station. Returns false if it wasn't watching it
+func (c *connection) stopWatching(stationNum uint16) bool {
+	c.watchingMutex.Lock()
+	defer c.watchingMutex.Unlock()
+	if !c.watching[stationNum] {
+		return false
+	}
+	delete(c.watching, stationNum)
+	return true
+}
+
+// isWatching returns whether the client watches a station
+func (c *connection) isWatching(stationNum uint16) bool {
+	c.watchingMutex.Lock()
+	defer c.watchingMutex.Unlock()
+	return c.watching[stationNum]
+}
+
+// getWatching returns the stations the client watches in order
+func (c *connection) getWatching() []uint16 {
+	c.watchingMutex.Lock()
+	defer c.watchingMutex.Unlock()
+	stationNums := make([]uint16, 0)
+	for stationNum := range c.watching {
+		stationNums = append(stationNums, stationNum)
+	}
+	sort.Slice(stationNums, func(i, j int) bool {
+		return stationNums[i] < stationNums[j]
+	})
+	return stationNums
+}
+
+// forwardWatched sends the song changes of the watched stations to the client until the connection closes
+func (c *connection) forwardWatched() {
+	for {
+		select {
+		case <-c.closedChan:
+			return
+		case change := <-c.watcher.Changes:
+			// the change may have been queued before the client stopped watching
+			if c.isWatching(change.Station) {
+				c.sendWatchedSong(change.Station, change.Song)
+			}
 		}
 	}
 }
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/IMaloney/snowcast/pkg/utils"
+)
+
+// millisecondsOr returns the milliseconds as a duration, or the fallback milliseconds if they are 0
+func millisecondsOr(milliseconds, fallback int) time.Duration {
+	if milliseconds == 0 {
+		milliseconds = fallback
+	}
+	return time.Duration(milliseconds) * time.Millisecond
+}
+
+// readDeadline returns when the server stops waiting on the next command of a client and why. Clients have to say
+// hello soon after connecting and tune in to or watch a station soon after that. Clients that send heartbeats have
+// to keep sending them. A zero time waits for as long as the tcp keepalive probes sent by Listen get answered
+func (s *Server) readDeadline(remoteAddr net.Addr, connectedAt time.Time, heartbeating bool) (time.Time, string) {
+	s.connectionsMutex.RLock()
+	conn, ok := s.connections[remoteAddr]
+	s.connectionsMutex.RUnlock()
+	if !ok {
+		return connectedAt.Add(s.helloTimeout), fmt.Sprintf("No hello received within %v", s.helloTimeout)
+	}
+	deadline, reason := time.Time{}, ""
+	if !conn.tunedIn.Load() {
+		deadline, reason = conn.helloTime.Add(s.idleTimeout), fmt.Sprintf("Did not tune in to a station within %v", s.idleTimeout)
+	}
+	if heartbeating {
+		missed := time.Now().Add(s.heartbeatInterval * time.Duration(s.heartbeatMisses))
+		if deadline.IsZero() || missed.Before(deadline) {
+			deadline, reason = missed, fmt.Sprintf("Missed %d heartbeats", s.heartbeatMisses)
+		}
+	}
+	return deadline, reason
+}
+
+// answerPings answers the heartbeats at the front of a command. Each answer tells the client the heartbeat interval
+// the server expects, so clients that ping less often speed up. A heartbeat can arrive stuck to the command sent
+// after it, so the rest of the command is returned
+func (s *Server) answerPings(conn *net.TCPConn, buffer []byte) ([]byte, error) {
+	for len(buffer) > 0 && utils.CommandType(buffer[0]) == utils.Ping {
+		message, err := utils.CreatePongMessage(uint32(s.heartbeatInterval / time.Millisecond))
+		if err != nil {
+			return nil, err
+		}
+		_, err = conn.Write(message)
+		if err != nil {
+			return nil, err
+		}
+		buffer = buffer[1:]
+	}
+	return buffer, nil
+}
+
+// timeOut tells a client why it is being disconnected and disconnects it
+func (s *Server) timeOut(conn *net.TCPConn, reason string) {
+	message, _ := utils.CreateInvalidCommandMessage(reason)
+	conn.Write(message)
+	s.connectionsMutex.RLock()
+	_, ok := s.connections[conn.RemoteAddr()]
+	s.connectionsMutex.RUnlock()
+	if !ok {
+		conn.Close()
+		return
+	}
+	s.removeConnection(conn.RemoteAddr())
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/IMaloney/snowcast/pkg/config"
+	"github.com/IMaloney/snowcast/pkg/utils"
+)
+
+// Reload applies the differences between the running stations and the config. Stations are matched by name.
+// A result is returned for every change made or skipped
+func (s *Server) Reload(cfg *config.Config) []string {
+	results := make([]string, 0)
+	if cfg.Listen != s.listenAddr {
+		results = append(results, fmt.Sprintf("listen address %s needs a restart to take effect", cfg.Listen))
+	}
+	if cfg.Features.ExtraCredit != s.extraCredit {
+		results = append(results, "extra credit toggle needs a restart to take effect")
+	}
+	if cfg.SourceListen != s.sourceListen {
+		results = append(results, fmt.Sprintf("source listen address %s needs a restart to take effect", cfg.SourceListen))
+	}
+	if cfg.SourcePassword != s.sourcePassword {
+		results = append(results, "source password needs a restart to take effect")
+	}
+	if cfg.StateFile != s.stateFile {
+		results = append(results, fmt.Sprintf("state file %s needs a restart to take effect", cfg.StateFile))
+	}
+	heartbeatMisses := cfg.HeartbeatMisses
+	if heartbeatMisses == 0 {
+		heartbeatMisses = utils.HEARTBEATMISSES
+	}
+	if millisecondsOr(cfg.HeartbeatInterval, utils.HEARTBEATTIME) != s.heartbeatInterval || heartbeatMisses != s.heartbeatMisses ||
+		millisecondsOr(cfg.HelloTimeout, utils.HELLOTIMEOUT) != s.helloTimeout || millisecondsOr(cfg.IdleTimeout, utils.IDLETIMEOUT) != s.idleTimeout {
+		results = append(results, "heartbeats and timeouts need a restart to take effect")
+	}
+	if cfg.AdminPassword != s.adminPassword.Load() {
+		s.adminPassword.Store(cfg.AdminPassword)
+		results = append(results, "updated the admin password")
+	}
+	running := s.radio.GetStationNames()
+	// stations replaced for a new live feed keep their number
+	replaced := make(map[string]uint16)
+	for _, stationConfig := range cfg.Stations {
+		stationNum, ok := running[stationConfig.Name]
+		if !ok {
+			continue
+		}
+		delete(running, stationConfig.Name)
+		liveFeed, err := s.radio.GetLiveFeed(stationNum)
+		if err != nil {
+			results = append(results, fmt.Sprintf("could not update station %s. %v", stationConfig.Name, err))
+			continue
+		}
+		if liveFeed == stationConfig.Live {
+			err = s.radio.UpdateStation(stationNum, stationConfig)
+			if err != nil {
+				results = append(results, fmt.Sprintf("could not update station %s. %v", stationConfig.Name, err))
+			} else {
+				results = append(results, fmt.Sprintf("updated station %d (%s)", stationNum, stationConfig.Name))
+			}
+			continue
+		}
+		// a new live feed needs a new station
+		err = s.RemoveStation(stationNum)
+		if err != nil {
+			results = append(results, fmt.Sprintf("could not replace station %s. %v", stationConfig.Name, err))
+			continue
+		}
+		replaced[stationConfig.Name] = stationNum
+		results = append(results, fmt.Sprintf("removed station %d (%s) to replace its live feed", stationNum, stationConfig.Name))
+	}
+	// whatever is left running isn't in the config anymore
+	for name, stationNum := range running {
+		err := s.RemoveStation(stationNum)
+		if err != nil {
+			results = append(results, fmt.Sprintf("could not remove station %d (%s). %v", stationNum, name, err))
+		} else {
+			results = append(results, fmt.Sprintf("removed station %d (%s)", stationNum, name))
+		}
+	}
+	existing := s.radio.GetStationNames()
+	for _, stationConfig := range cfg.Stations {
+		if _, ok := existing[stationConfig.Name]; ok {
+			continue
+		}
+		if stationNum, ok := replaced[stationConfig.Name]; ok && stationConfig.ID == nil {
+			id := int(stationNum)
+			stationConfig.ID = &id
+		}
+		stationNum, err := s.addConfiguredStation(stationConfig)
+		if err != nil {
+			results = append(results, fmt.Sprintf("could not add station %s. %v", stationConfig.Name, err))
+		} else {
+			results = append(results, fmt.Sprintf("added station %d (%s)", stationNum, stationConfig.Name))
+		}
+	}
+	return results
+}
@@ -3,57 +3,158 @@ package server
 import (
 	"encoding/binary"
 	"fmt"
+	"math"
 	"net"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/IMaloney/snowcast/pkg/config"
 	"github.com/IMaloney/snowcast/pkg/radio"
 	"github.com/IMaloney/snowcast/pkg/utils"
+	"go.uber.org/atomic"
 )
 
 type Server struct {
-	serverPort       string
+	listenAddr       string
 	messageChan      chan string
 	tcpListener      *net.TCPListener
 	connections      map[net.Addr]*connection
 	connectionsMutex sync.RWMutex
 	radio            *radio.Radio
 	extraCredit      bool
+	sourceListen     string
+	sourceListener   *net.TCPListener
+	sourcePassword   string
+	stateFile        string
+	adminPassword    *atomic.String
+	// nickname each muted address was muted as
+	muted      map[string]string
+	mutedMutex sync.RWMutex
+	// how long clients have to send their commands before they are disconnected
+	heartbeatInterval time.Duration
+	heartbeatMisses   int
+	helloTimeout      time.Duration
+	idleTimeout       time.Duration
+	quitChan          chan struct{}
 }
 
-// CreateServer returns a server struct
-func CreateServer(port string, files []string, msgChan chan string, extraCredit bool) (*Server, error) {
-	radio, err := radio.CreateRadio(files, extraCredit)
+// CreateServer returns a server struct built from the config
+func CreateServer(cfg *config.Config, msgChan chan string) (*Server, error) {
+	radio, err := radio.CreateRadio(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("Could not create radio. Error: %v", err)
 	}
-	addr, err := net.ResolveTCPAddr("tcp", ":"+port)
+	addr, err := net.ResolveTCPAddr("tcp", cfg.Listen)
 	if err != nil {
-		return nil, fmt.Errorf("could not resolve tcp address from port %s. Error: %v", port, err)
+		radio.Quit()
+		return nil, fmt.Errorf("could not resolve tcp address %s. Error: %v", cfg.Listen, err)
 	}
 	tcpListener, err := net.ListenTCP("tcp", addr)
 	if err != nil {
+		radio.Quit()
 		return nil, fmt.Errorf("could not resolve tcp listener from addr %s", addr.String())
 	}
-	return &Server{
-		serverPort:  port,
-		radio:       radio,
-		tcpListener: tcpListener,
-		extraCredit: extraCredit,
-		messageChan: msgChan,
-		connections: make(map[net.Addr]*connection),
-	}, nil
+	server := &Server{
+		listenAddr:        cfg.Listen,
+		radio:             radio,
+		tcpListener:       tcpListener,
+		extraCredit:       cfg.Features.ExtraCredit,
+		messageChan:       msgChan,
+		connections:       make(map[net.Addr]*connection),
+		sourceListen:      cfg.SourceListen,
+		sourcePassword:    cfg.SourcePassword,
+		stateFile:         cfg.StateFile,
+		adminPassword:     atomic.NewString(cfg.AdminPassword),
+		muted:             make(map[string]string),
+		quitChan:          make(chan struct{}),
+		heartbeatInterval: millisecondsOr(cfg.HeartbeatInterval, utils.HEARTBEATTIME),
+		heartbeatMisses:   cfg.HeartbeatMisses,
+		helloTimeout:      millisecondsOr(cfg.HelloTimeout, utils.HELLOTIMEOUT),
+		idleTimeout:       millisecondsOr(cfg.IdleTimeout, utils.IDLETIMEOUT),
+	}
+	if server.heartbeatMisses == 0 {
+		server.heartbeatMisses = utils.HEARTBEATMISSES
+	}
+	if server.stateFile != "" {
+		go server.saveStatePeriodically()
+	}
+	go server.logWarnings()
+	if server.extraCredit && cfg.SourceListen != "" {
+		err = server.startSourceListener(cfg.SourceListen, cfg.SourcePassword)
+		if err != nil {
+			server.Quit()
+			tcpListener.Close()
+			return nil, err
+		}
+	}
+	return server, nil
+}
+
+// Shutdown tells every client why the server is going away and how many seconds until it is back, then quits.
+// 0 seconds means the server isn't coming back
+func (s *Server) Shutdown(reason string, reconnectAfter uint16) {
+	s.connectionsMutex.RLock()
+	for _, conn := range s.connections {
+		conn.sendServerShutdown(reason, reconnectAfter)
+	}
+	s.connectionsMutex.RUnlock()
+	s.Quit()
 }
 
 // Quit quits the server
 func (s *Server) Quit() {
+	close(s.quitChan)
+	if s.sourceListener != nil {
+		s.sourceListener.Close()
+	}
+	if s.stateFile != "" {
+		err := s.radio.SaveState(s.stateFile)
+		if err != nil {
+			fmt.Printf("Could not save station positions. %v\n", err)
+		}
+	}
 	s.radio.Quit()
-	s.connectionsMutex.Lock()
+	// removeConnection takes the lock itself
+	s.connectionsMutex.RLock()
+	connAddrs := make([]net.Addr, 0)
 	for connAddr := range s.connections {
+		connAddrs = append(connAddrs, connAddr)
+	}
+	s.connectionsMutex.RUnlock()
+	for _, connAddr := range connAddrs {
 		s.removeConnection(connAddr)
 	}
-	s.connectionsMutex.Unlock()
+}
+
+// saveStatePeriodically snapshots the position of every station to the state file until the server quits
+func (s *Server) saveStatePeriodically() {
+	ticker := time.NewTicker(utils.STATETIME * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.quitChan:
+			return
+		case <-ticker.C:
+			err := s.radio.SaveState(s.stateFile)
+			if err != nil {
+				s.messageChan <- fmt.Sprintf("Could not save station positions. %v", err)
+			}
+		}
+	}
+}
+
+// logWarnings logs the warnings of the stations until the server quits
+func (s *Server) logWarnings() {
+	for {
+		select {
+		case <-s.quitChan:
+			return
+		case warning := <-s.radio.Warnings():
+			s.messageChan <- warning
+		}
+	}
 }
 
 // connectUDP connects the udp address to the connection
@@ -70,7 +171,7 @@ func connectUDP(conn *net.TCPConn, udpPort string) (*net.UDPConn, error) {
 }
 
 // handleHandshake handles a handshake request
-func (s *Server) handleHandshake(conn *net.TCPConn, udpPort string, numClient int) error {
+func (s *Server) handleHandshake(conn *net.TCPConn, udpPort, nickname string, numClient int) error {
 	s.connectionsMutex.RLock()
 	if _, ok := s.connections[conn.RemoteAddr()]; ok {
 		s.connections[conn.RemoteAddr()].sendInvalidRequest("Client cannot send more than one hello message")
@@ -94,10 +195,14 @@ func (s *Server) handleHandshake(conn *net.TCPConn, udpPort string, numClient in
 		udpConn.Close()
 		return fmt.Errorf("Could not write hello message to client. Error: %v", err)
 	}
-	connection := createConnection(conn, udpConn, conn.RemoteAddr(), numClient)
+	connection := createConnection(conn, udpConn, conn.RemoteAddr(), numClient, s.extraCredit)
 	s.connectionsMutex.Lock()
+	connection.nickname = s.uniqueNickname(nickname, numClient)
 	s.connections[conn.RemoteAddr()] = connection
 	s.connectionsMutex.Unlock()
+	if s.extraCredit {
+		go connection.forwardWatched()
+	}
 	return nil
 }
 
@@ -114,19 +219,180 @@ func (s *Server) handleSetStationRequest(connAddr net.Addr, stationNum uint16) e
 		return err
 	}
 	s.connectionsMutex.RLock()
-	curStation := s.connections[connAddr].currentStation
-	if s.connections[connAddr].isListening() {
-		s.connections[connAddr].stopStreamingChan <- struct{}{}
-		// leaving station
-		s.radio.LeaveStation(uint16(curStation), connAddr)
-		s.connections[connAddr].listening.Store(false)
+	err = s.tuneIn(s.connections[connAddr], stationNum)
+	if err != nil {
+		otherErr := s.connections[connAddr].sendInvalidRequest(err.Error())
+		s.connectionsMutex.RUnlock()
+		if otherErr != nil {
+			return otherErr
+		}
+		return err
+	}
+	err = s.announceStation(s.connections[connAddr], stationNum, songName)
+	s.connectionsMutex.RUnlock()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// tuneIn moves a connection from the station it is listening to onto another station and starts streaming it.
+// connectionsMutex must be held
+func (s *Server) tuneIn(conn *connection, stationNum uint16) error {
+	if conn.isListening() {
+		s.leaveStation(conn)
 	}
 	// updating current station number here
-	s.connections[connAddr].currentStation = stationNum
+	conn.currentStation = stationNum
 
 	// joining station
-	err = s.radio.JoinStation(stationNum, connAddr, s.connections[connAddr].subscriber)
+	err := s.radio.JoinStation(stationNum, conn.addr, conn.subscriber)
+	if err != nil {
+		return err
+	}
+	conn.tunedIn.Store(true)
+	// streaming station here
+	go conn.streamStation()
+	return nil
+}
+
+// leaveStation stops a listening connection streaming its station and takes it off the station
+func (s *Server) leaveStation(conn *connection) {
+	conn.stopStreamingChan <- struct{}{}
+	s.radio.LeaveStation(conn.currentStation, conn.addr)
+	conn.listening.Store(false)
+}
+
+// handleStopListeningRequest stops the client hearing its station while it stays connected
+func (s *Server) handleStopListeningRequest(connAddr net.Addr) error {
+	s.connectionsMutex.RLock()
+	defer s.connectionsMutex.RUnlock()
+	conn := s.connections[connAddr]
+	if !conn.isListening() {
+		return conn.sendStopDenied("You are not listening to a station")
+	}
+	s.leaveStation(conn)
+	return conn.sendStoppedListening(conn.currentStation)
+}
+
+// announceStation announces the song playing on the station a connection just tuned in to
+func (s *Server) announceStation(conn *connection, stationNum uint16, songName string) error {
+	err := conn.sendAnnounce(songName)
+	if err != nil {
+		return err
+	}
+	// a listener joining a paused station should know why it hears nothing
+	if paused, _ := s.radio.IsPaused(stationNum); paused {
+		return conn.sendPauseChange(true)
+	}
+	return nil
+}
+
+// MigrateListeners moves every listener of one station onto another and announces the song playing there.
+// Clients don't need to set their station again. Returns how many listeners moved and how many could not join
+func (s *Server) MigrateListeners(from, to uint16) (int, int, error) {
+	if from == to {
+		return 0, 0, fmt.Errorf("listeners are already on station %d", to)
+	}
+	songName, err := s.radio.GetSongName(to)
+	if err != nil {
+		return 0, 0, err
+	}
+	s.connectionsMutex.RLock()
+	defer s.connectionsMutex.RUnlock()
+	moved, failed := 0, 0
+	for _, conn := range s.connections {
+		if !conn.isListening() || conn.currentStation != from {
+			continue
+		}
+		err = s.tuneIn(conn, to)
+		if err != nil {
+			// the station may have filled up. The listener hears nothing until it picks another station
+			failed++
+			continue
+		}
+		s.announceStation(conn, to, songName)
+		moved++
+	}
+	return moved, failed, nil
+}
+
+// DrainStation stops a station taking new listeners, counts down to its shutdown while its current song finishes,
+// then removes it. Listeners move to the fallback station first if one is given
+func (s *Server) DrainStation(stationNum uint16, fallback *uint16) error {
+	if fallback != nil {
+		if *fallback == stationNum {
+			return fmt.Errorf("a station cannot fall back on itself")
+		}
+		if _, err := s.radio.GetSongName(*fallback); err != nil {
+			return err
+		}
+	}
+	done, timeLeft, err := s.radio.DrainStation(stationNum)
 	if err != nil {
+		return err
+	}
+	go s.finishDraining(stationNum, fallback, done, timeLeft)
+	return nil
+}
+
+// finishDraining sends countdown notices to the listeners of a draining station until its song is over, then
+// removes the station
+func (s *Server) finishDraining(stationNum uint16, fallback *uint16, done <-chan struct{}, timeLeft time.Duration) {
+	deadline := time.Now().Add(timeLeft)
+	// a live station or a station that lost track of its song still goes away
+	giveUp := time.After(timeLeft + utils.LIVETIMEOUT*time.Millisecond)
+	ticker := time.NewTicker(utils.DRAINNOTICE * time.Millisecond)
+	defer ticker.Stop()
+	s.sendDrainNotice(stationNum, deadline)
+	for waiting := true; waiting; {
+		select {
+		case <-done:
+			waiting = false
+		case <-giveUp:
+			waiting = false
+		case <-s.quitChan:
+			return
+		case <-ticker.C:
+			s.sendDrainNotice(stationNum, deadline)
+		}
+	}
+	if fallback != nil {
+		moved, failed, err := s.MigrateListeners(stationNum, *fallback)
+		if err != nil {
+			s.messageChan <- fmt.Sprintf("Could not move listeners of station %d. %v", stationNum, err)
+		} else {
+			s.messageChan <- fmt.Sprintf("Moved %d listeners from station %d to station %d. %d could not join it", moved, stationNum, *fallback, failed)
+		}
+	}
+	err := s.RemoveStation(stationNum)
+	if err != nil {
+		s.messageChan <- fmt.Sprintf("Could not remove station %d. %v", stationNum, err)
+		return
+	}
+	s.messageChan <- fmt.Sprintf("Station %d drained and removed", stationNum)
+}
+
+// sendDrainNotice tells the listeners of a draining station how long it has left
+func (s *Server) sendDrainNotice(stationNum uint16, deadline time.Time) {
+	secondsLeft := time.Until(deadline).Round(time.Second) / time.Second
+	if secondsLeft < 0 {
+		secondsLeft = 0
+	}
+	s.connectionsMutex.RLock()
+	for _, conn := range s.connections {
+		if conn.isListening() && conn.currentStation == stationNum {
+			conn.sendStationDraining(stationNum, uint16(secondsLeft))
+		}
+	}
+	s.connectionsMutex.RUnlock()
+}
+
+// handleGetStationSongsRequest handles a request for the songs list
+func (s *Server) handleGetStationSongsRequest(connAddr net.Addr, stationNumber uint16) error {
+	songs, err := s.radio.GetStationSongs(stationNumber)
+	if err != nil {
+		s.connectionsMutex.RLock()
 		otherErr := s.connections[connAddr].sendInvalidRequest(err.Error())
 		s.connectionsMutex.RUnlock()
 		if otherErr != nil {
@@ -134,9 +400,8 @@ func (s *Server) handleSetStationRequest(connAddr net.Addr, stationNum uint16) e
 		}
 		return err
 	}
-	// streaming station here
-	go s.connections[connAddr].streamStation()
-	err = s.connections[connAddr].sendAnnounce(songName)
+	s.connectionsMutex.RLock()
+	err = s.connections[connAddr].sendSongsList(songs)
 	s.connectionsMutex.RUnlock()
 	if err != nil {
 		return err
@@ -144,9 +409,9 @@ func (s *Server) handleSetStationRequest(connAddr net.Addr, stationNum uint16) e
 	return nil
 }
 
-// handleGetStationSongsRequest handles a request for the songs list
-func (s *Server) handleGetStationSongsRequest(connAddr net.Addr, stationNumber uint16) error {
-	songs, err := s.radio.GetStationSongs(stationNumber)
+// handleGetPlaylistRequest handles a request for the upcoming songs of a station
+func (s *Server) handleGetPlaylistRequest(connAddr net.Addr, stationNumber, numSongs uint16) error {
+	songs, err := s.radio.GetUpcomingSongs(stationNumber, int(numSongs))
 	if err != nil {
 		s.connectionsMutex.RLock()
 		otherErr := s.connections[connAddr].sendInvalidRequest(err.Error())
@@ -165,6 +430,148 @@ func (s *Server) handleGetStationSongsRequest(connAddr net.Addr, stationNumber u
 	return nil
 }
 
+// handleGetShowsRequest handles a request for the current and next show of a station
+func (s *Server) handleGetShowsRequest(connAddr net.Addr, stationNumber uint16) error {
+	currentShow, nextShow, nextStart, err := s.radio.GetShows(stationNumber)
+	if err != nil {
+		s.connectionsMutex.RLock()
+		otherErr := s.connections[connAddr].sendInvalidRequest(err.Error())
+		s.connectionsMutex.RUnlock()
+		if otherErr != nil {
+			return otherErr
+		}
+		return err
+	}
+	// the start is sent as minutes from now so it holds on any day and in any time zone. Rounding up lands the
+	// client on the minute the show starts
+	nextIn := 0
+	if nextShow != "" {
+		nextIn = int(math.Ceil(time.Until(nextStart).Minutes()))
+	}
+	s.connectionsMutex.RLock()
+	err = s.connections[connAddr].sendShowInfo(currentShow, nextShow, uint16(nextIn))
+	s.connectionsMutex.RUnlock()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// handleListStationsRequest sends the client the number, name, description, current song and listener count of every station
+func (s *Server) handleListStationsRequest(connAddr net.Addr) error {
+	stations := make([]utils.StationEntry, 0)
+	for _, station := range s.radio.ListStations() {
+		stations = append(stations, utils.StationEntry{
+			Station:     station.Number,
+			Name:        station.Name,
+			Description: station.Description,
+			Song:        station.Song,
+			Listeners:   uint32(station.Listeners),
+		})
+	}
+	s.connectionsMutex.RLock()
+	err := s.connections[connAddr].sendStationList(stations)
+	s.connectionsMutex.RUnlock()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// handleGetAllStationSongsRequest sends the client the songs and the song playing of every station at once
+func (s *Server) handleGetAllStationSongsRequest(connAddr net.Addr) error {
+	stations := make([]utils.StationSongs, 0)
+	for _, station := range s.radio.GetAllStationSongs() {
+		stations = append(stations, utils.StationSongs{
+			Station:    station.Number,
+			Name:       station.Name,
+			NowPlaying: station.NowPlaying,
+			Songs:      station.Songs,
+		})
+	}
+	s.connectionsMutex.RLock()
+	err := s.connections[connAddr].sendAllStationSongs(stations)
+	s.connectionsMutex.RUnlock()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// handleSearchSongsRequest sends the client the songs on every station whose name or tags match the query
+func (s *Server) handleSearchSongsRequest(connAddr net.Addr, query string) error {
+	matches := make([]utils.SongMatch, 0)
+	for _, match := range s.radio.SearchSongs(query, utils.SEARCHLIMIT) {
+		matches = append(matches, utils.SongMatch{
+			Station:  match.Station,
+			Position: uint16(match.Position),
+			OnAir:    match.OnAir,
+			Song:     match.Song,
+			Tags:     match.Tags,
+		})
+	}
+	s.connectionsMutex.RLock()
+	err := s.connections[connAddr].sendSearchResults(matches)
+	s.connectionsMutex.RUnlock()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// handleRequestSongRequest queues the song a client asked for on a station. A client can have one song queued every
+// REQUESTTIME milliseconds, and is told when the song is queued and when it starts
+func (s *Server) handleRequestSongRequest(connAddr net.Addr, stationNumber uint16, songName string) error {
+	s.connectionsMutex.RLock()
+	conn := s.connections[connAddr]
+	s.connectionsMutex.RUnlock()
+	wait := utils.REQUESTTIME*time.Millisecond - time.Since(conn.lastRequest)
+	if wait > 0 {
+		return conn.sendRequestDenied(fmt.Sprintf("You can request another song in %d seconds", int(wait.Seconds())+1))
+	}
+	position, err := s.radio.RequestSong(stationNumber, songName, func() {
+		s.connectionsMutex.RLock()
+		defer s.connectionsMutex.RUnlock()
+		// the client may have left before its song came up
+		if requester, ok := s.connections[connAddr]; ok {
+			requester.sendRequestPlaying(stationNumber, songName)
+		}
+	})
+	if err != nil {
+		return conn.sendRequestDenied(err.Error())
+	}
+	conn.lastRequest = time.Now()
+	return conn.sendRequestQueued(stationNumber, uint16(position), songName)
+}
+
+// handleVoteSkipRequest counts the client's vote to skip the song on the station it listens to. The new tally
+// reaches every listener of the station through their subscriptions
+func (s *Server) handleVoteSkipRequest(connAddr net.Addr) error {
+	s.connectionsMutex.RLock()
+	conn := s.connections[connAddr]
+	s.connectionsMutex.RUnlock()
+	if !conn.isListening() {
+		return conn.sendVoteDenied("Tune in to a station to vote to skip its song")
+	}
+	tally, err := s.radio.VoteSkip(conn.currentStation, conn.addr)
+	if err != nil {
+		return conn.sendVoteDenied(err.Error())
+	}
+	if tally.Votes >= tally.Needed {
+		s.messageChan <- fmt.Sprintf("listeners voted to skip the song on station %d", conn.currentStation)
+	}
+	return nil
+}
+
+// SetPlayMode changes the play mode of a station
+func (s *Server) SetPlayMode(stationNum uint16, modeName string, noRepeat int) error {
+	mode, err := radio.ParsePlayMode(modeName)
+	if err != nil {
+		return err
+	}
+	return s.radio.SetPlayMode(stationNum, mode, noRepeat)
+}
+
 // removeConnection removes a connection from the server
 func (s *Server) removeConnection(remoteAddr net.Addr) {
 	s.connectionsMutex.Lock()
@@ -173,6 +580,7 @@ func (s *Server) removeConnection(remoteAddr net.Addr) {
 	if _, ok := s.connections[remoteAddr]; !ok {
 		return
 	}
+	s.unwatchAll(s.connections[remoteAddr])
 	// leave station first if you are listening to something
 	if s.connections[remoteAddr].isListening() {
 		curStation := s.connections[remoteAddr].currentStation
@@ -192,12 +600,27 @@ func (s *Server) AddStation(vals []string) error {
 	if err != nil {
 		return err
 	}
+	s.announceNewStation(stationNum)
+	return nil
+}
+
+// addConfiguredStation adds a station described by a config to the radio
+func (s *Server) addConfiguredStation(stationConfig config.StationConfig) (uint16, error) {
+	stationNum, err := s.radio.AddStationFromConfig(stationConfig)
+	if err != nil {
+		return 0, err
+	}
+	s.announceNewStation(stationNum)
+	return stationNum, nil
+}
+
+// announceNewStation tells every client about a new station
+func (s *Server) announceNewStation(stationNum uint16) {
 	s.connectionsMutex.RLock()
 	for _, connection := range s.connections {
 		connection.sendNewStation(stationNum, s.radio.GetNumStations())
 	}
 	s.connectionsMutex.RUnlock()
-	return nil
 }
 
 // RemoveStation removes a station
@@ -209,6 +632,8 @@ func (s *Server) RemoveStation(stationNum uint16) error {
 	s.connectionsMutex.RLock()
 	for _, connection := range s.connections {
 		connection.sendStationShutDown(stationNum, s.radio.GetNumStations())
+		// the station took its watchers with it
+		connection.stopWatching(stationNum)
 	}
 	s.connectionsMutex.RUnlock()
 	return nil
@@ -217,31 +642,60 @@ func (s *Server) RemoveStation(stationNum uint16) error {
 // handeConnection handles a client connection
 func (s *Server) handleConnection(conn *net.TCPConn, numClient int) {
 	remoteAddr := conn.RemoteAddr()
+	connectedAt := time.Now()
+	// clients that send heartbeats are disconnected once they stop
+	heartbeating := false
 	for {
+		deadline, reason := s.readDeadline(remoteAddr, connectedAt, heartbeating)
+		conn.SetReadDeadline(deadline)
 		buffer := make([]byte, utils.BUFFSIZE)
-		_, err := conn.Read(buffer)
+		n, err := conn.Read(buffer)
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			s.messageChan <- fmt.Sprintf("session id %d: %s, closing connection", numClient, reason)
+			s.timeOut(conn, reason)
+			return
+		}
 		if err != nil {
 			s.messageChan <- fmt.Sprintf("Receive error on Client: %s. Error: %v, closing connection\n", remoteAddr.String(), err)
 			s.removeConnection(remoteAddr)
 			return
 		}
+		if s.extraCredit && utils.CommandType(buffer[0]) == utils.Ping {
+			heartbeating = true
+			rest, err := s.answerPings(conn, buffer[:n])
+			if err != nil {
+				s.removeConnection(remoteAddr)
+				return
+			}
+			if len(rest) == 0 {
+				continue
+			}
+			buffer = append(rest, make([]byte, utils.BUFFSIZE-len(rest))...)
+		}
 		commandType := utils.CommandType(uint8(buffer[0]))
+		// every command but hello needs the connection hello sets up
+		s.connectionsMutex.RLock()
+		_, saidHello := s.connections[remoteAddr]
+		s.connectionsMutex.RUnlock()
+		if commandType != utils.Hello && !saidHello {
+			msg, _ := utils.CreateInvalidCommandMessage(fmt.Sprintf("Client %d cannot send a message before saying hello", numClient))
+			conn.Write(msg)
+			conn.Close()
+			return
+		}
 		switch commandType {
 		case utils.Hello:
 			udpPort := strconv.Itoa(int(binary.BigEndian.Uint16(buffer[1:3])))
-			err := s.handleHandshake(conn, udpPort, numClient)
+			// only extra credit clients send a nickname
+			nicknameSize := int(buffer[3])
+			nickname := string(buffer[4 : 4+nicknameSize])
+			err := s.handleHandshake(conn, udpPort, nickname, numClient)
 			if err != nil {
 				return
 			}
 			s.messageChan <- fmt.Sprintf("session id %d: HELLO received; sending WELCOME, expecting SET_STATION", numClient)
 		case utils.SetStation:
 			// logic to change song here
-			if _, ok := s.connections[remoteAddr]; !ok {
-				msg, _ := utils.CreateInvalidCommandMessage(fmt.Sprintf("Client %d cannot send a message before saying hello", numClient))
-				conn.Write(msg)
-				conn.Close()
-				return
-			}
 			stationNumber := binary.BigEndian.Uint16(buffer[1:])
 			msg := fmt.Sprintf("session id %d: received SET_STATION to station %d", numClient, stationNumber)
 			s.messageChan <- msg
@@ -264,6 +718,179 @@ func (s *Server) handleConnection(conn *net.TCPConn, numClient int) {
 				s.clientCommandNotRecognized(remoteAddr, commandType)
 				return
 			}
+		case utils.GetPlaylist:
+			if s.extraCredit {
+				stationNumber := binary.BigEndian.Uint16(buffer[1:3])
+				numSongs := binary.BigEndian.Uint16(buffer[3:5])
+				msg := fmt.Sprintf("session id %d: received GET_PLAYLIST for %d songs on station %d", numClient, numSongs, stationNumber)
+				s.messageChan <- msg
+				err := s.handleGetPlaylistRequest(remoteAddr, stationNumber, numSongs)
+				if err != nil {
+					s.removeConnection(remoteAddr)
+					return
+				}
+			} else {
+				s.clientCommandNotRecognized(remoteAddr, commandType)
+				return
+			}
+		case utils.GetShows:
+			if s.extraCredit {
+				stationNumber := binary.BigEndian.Uint16(buffer[1:3])
+				msg := fmt.Sprintf("session id %d: received GET_SHOWS for station %d", numClient, stationNumber)
+				s.messageChan <- msg
+				err := s.handleGetShowsRequest(remoteAddr, stationNumber)
+				if err != nil {
+					s.removeConnection(remoteAddr)
+					return
+				}
+			} else {
+				s.clientCommandNotRecognized(remoteAddr, commandType)
+				return
+			}
+		case utils.ListStations:
+			if s.extraCredit {
+				s.messageChan <- fmt.Sprintf("session id %d: received LIST_STATIONS", numClient)
+				err := s.handleListStationsRequest(remoteAddr)
+				if err != nil {
+					s.removeConnection(remoteAddr)
+					return
+				}
+			} else {
+				s.clientCommandNotRecognized(remoteAddr, commandType)
+				return
+			}
+		case utils.GetAllStationSongs:
+			if s.extraCredit {
+				s.messageChan <- fmt.Sprintf("session id %d: received GET_ALL_STATION_SONGS", numClient)
+				err := s.handleGetAllStationSongsRequest(remoteAddr)
+				if err != nil {
+					s.removeConnection(remoteAddr)
+					return
+				}
+			} else {
+				s.clientCommandNotRecognized(remoteAddr, commandType)
+				return
+			}
+		case utils.SearchSongs:
+			if s.extraCredit {
+				querySize := int(buffer[1])
+				query := string(buffer[2 : 2+querySize])
+				s.messageChan <- fmt.Sprintf("session id %d: received SEARCH_SONGS for %s", numClient, query)
+				err := s.handleSearchSongsRequest(remoteAddr, query)
+				if err != nil {
+					s.removeConnection(remoteAddr)
+					return
+				}
+			} else {
+				s.clientCommandNotRecognized(remoteAddr, commandType)
+				return
+			}
+		case utils.RequestSong:
+			if s.extraCredit {
+				stationNumber := binary.BigEndian.Uint16(buffer[1:3])
+				songNameSize := int(buffer[3])
+				songName := string(buffer[4 : 4+songNameSize])
+				s.messageChan <- fmt.Sprintf("session id %d: received REQUEST_SONG for %s on station %d", numClient, songName, stationNumber)
+				err := s.handleRequestSongRequest(remoteAddr, stationNumber, songName)
+				if err != nil {
+					s.removeConnection(remoteAddr)
+					return
+				}
+			} else {
+				s.clientCommandNotRecognized(remoteAddr, commandType)
+				return
+			}
+		case utils.VoteSkip:
+			if s.extraCredit {
+				s.messageChan <- fmt.Sprintf("session id %d: received VOTE_SKIP", numClient)
+				err := s.handleVoteSkipRequest(remoteAddr)
+				if err != nil {
+					s.removeConnection(remoteAddr)
+					return
+				}
+			} else {
+				s.clientCommandNotRecognized(remoteAddr, commandType)
+				return
+			}
+		case utils.ChatMessage:
+			if s.extraCredit {
+				textSize := int(buffer[1])
+				text := string(buffer[2 : 2+textSize])
+				err := s.handleChatRequest(remoteAddr, text)
+				if err != nil {
+					s.removeConnection(remoteAddr)
+					return
+				}
+			} else {
+				s.clientCommandNotRecognized(remoteAddr, commandType)
+				return
+			}
+		case utils.StopListening:
+			if s.extraCredit {
+				s.messageChan <- fmt.Sprintf("session id %d: received STOP_LISTENING", numClient)
+				err := s.handleStopListeningRequest(remoteAddr)
+				if err != nil {
+					s.removeConnection(remoteAddr)
+					return
+				}
+			} else {
+				s.clientCommandNotRecognized(remoteAddr, commandType)
+				return
+			}
+		case utils.Watch:
+			if s.extraCredit {
+				stationNums, err := utils.ParseWatchMessage(buffer)
+				if err != nil {
+					s.clientCommandNotRecognized(remoteAddr, commandType)
+					return
+				}
+				s.messageChan <- fmt.Sprintf("session id %d: received WATCH for stations %v", numClient, stationNums)
+				err = s.handleWatchRequest(remoteAddr, stationNums)
+				if err != nil {
+					s.removeConnection(remoteAddr)
+					return
+				}
+			} else {
+				s.clientCommandNotRecognized(remoteAddr, commandType)
+				return
+			}
+		case utils.Unwatch:
+			if s.extraCredit {
+				stationNums, err := utils.ParseWatchMessage(buffer)
+				if err != nil {
+					s.clientCommandNotRecognized(remoteAddr, commandType)
+					return
+				}
+				s.messageChan <- fmt.Sprintf("session id %d: received UNWATCH for stations %v", numClient, stationNums)
+				err = s.handleUnwatchRequest(remoteAddr, stationNums)
+				if err != nil {
+					s.removeConnection(remoteAddr)
+					return
+				}
+			} else {
+				s.clientCommandNotRecognized(remoteAddr, commandType)
+				return
+			}
+		case utils.Admin:
+			if s.extraCredit {
+				passwordSize := int(buffer[1])
+				commandSize := int(binary.BigEndian.Uint16(buffer[2:4]))
+				if 4+passwordSize+commandSize > len(buffer) {
+					s.clientCommandNotRecognized(remoteAddr, commandType)
+					return
+				}
+				password := string(buffer[4 : 4+passwordSize])
+				command := string(buffer[4+passwordSize : 4+passwordSize+commandSize])
+				s.messageChan <- fmt.Sprintf("session id %d: received ADMIN command %s", numClient, command)
+				err := s.handleAdminRequest(remoteAddr, password, command)
+				if err != nil {
+					s.removeConnection(remoteAddr)
+					return
+				}
+			} else {
+				s.clientCommandNotRecognized(remoteAddr, commandType)
+				return
+			}
 		default:
 			s.clientCommandNotRecognized(remoteAddr, commandType)
 			return
@@ -291,6 +918,9 @@ func (s *Server) Listen() {
 			continue
 		}
 		fmt.Printf("session id %d: new client connected; expecting HELLO\n", numClient)
+		// clients that never ping are probed by tcp instead, so their half-open connections are dropped too
+		conn.SetKeepAlive(true)
+		conn.SetKeepAlivePeriod(s.heartbeatInterval)
 		go s.handleConnection(conn, numClient)
 
 		numClient++
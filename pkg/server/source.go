@@ -0,0 +1,181 @@
+package server
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/IMaloney/snowcast/pkg/config"
+	"github.com/IMaloney/snowcast/pkg/radio"
+)
+
+const sourceUser = "source"
+
+type sourceStream struct {
+	reader     *bufio.Reader
+	conn       *net.TCPConn
+	stationNum uint16
+	msgChan    chan string
+	// attached says whether the station took the source. Nothing is read until it does
+	attached chan bool
+}
+
+// Read reads audio pushed by the source client
+func (ss *sourceStream) Read(p []byte) (int, error) {
+	if ss.attached != nil {
+		if !<-ss.attached {
+			return 0, io.EOF
+		}
+		ss.attached = nil
+	}
+	n, err := ss.reader.Read(p)
+	if err != nil {
+		ss.msgChan <- fmt.Sprintf("source %s: disconnected from station %d", ss.conn.RemoteAddr().String(), ss.stationNum)
+	}
+	return n, err
+}
+
+// Close closes the source client connection
+func (ss *sourceStream) Close() error {
+	return ss.conn.Close()
+}
+
+// startSourceListener listens for icecast style source clients on the given address
+func (s *Server) startSourceListener(listenAddr, password string) error {
+	addr, err := net.ResolveTCPAddr("tcp", listenAddr)
+	if err != nil {
+		return fmt.Errorf("could not resolve tcp address %s. Error: %v", listenAddr, err)
+	}
+	listener, err := net.ListenTCP("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("could not resolve tcp listener from addr %s", addr.String())
+	}
+	s.sourceListener = listener
+	s.sourcePassword = password
+	go s.listenForSources()
+	return nil
+}
+
+// listenForSources accepts source client connections
+func (s *Server) listenForSources() {
+	for {
+		conn, err := s.sourceListener.AcceptTCP()
+		if err != nil {
+			return
+		}
+		go s.handleSource(conn)
+	}
+}
+
+// sendSourceStatus writes an http style status line to the source client
+func sendSourceStatus(conn *net.TCPConn, proto, status string) error {
+	_, err := conn.Write([]byte(fmt.Sprintf("%s %s\r\n\r\n", proto, status)))
+	return err
+}
+
+// parseBasicAuth returns the user and password of a basic authorization header
+func parseBasicAuth(header string) (string, string, bool) {
+	if !strings.HasPrefix(header, "Basic ") {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(header, "Basic "))
+	if err != nil {
+		return "", "", false
+	}
+	credentials := strings.SplitN(string(decoded), ":", 2)
+	if len(credentials) != 2 {
+		return "", "", false
+	}
+	return credentials[0], credentials[1], true
+}
+
+// findMount returns the station a source mount refers to, either by station number or station name
+func (s *Server) findMount(mount string) (uint16, bool) {
+	if stationNum, ok := s.radio.GetStationByName(mount); ok {
+		return stationNum, true
+	}
+	num, err := strconv.Atoi(mount)
+	if err != nil || num < 0 {
+		return 0, false
+	}
+	if _, err := s.radio.GetSongName(uint16(num)); err != nil {
+		return 0, false
+	}
+	return uint16(num), true
+}
+
+// handleSource reads the handshake of a source client (SOURCE or PUT /[station]) then feeds the rest of the stream into the station
+func (s *Server) handleSource(conn *net.TCPConn) {
+	remoteAddr := conn.RemoteAddr().String()
+	reader := bufio.NewReader(conn)
+	textReader := textproto.NewReader(reader)
+	request, err := textReader.ReadLine()
+	if err != nil {
+		conn.Close()
+		return
+	}
+	fields := strings.Fields(request)
+	proto := "HTTP/1.0"
+	if len(fields) == 3 && strings.HasPrefix(fields[2], "HTTP/") {
+		proto = fields[2]
+	}
+	if len(fields) < 2 || (fields[0] != "SOURCE" && fields[0] != "PUT") {
+		sendSourceStatus(conn, proto, "400 Bad Request")
+		conn.Close()
+		return
+	}
+	headers, err := textReader.ReadMIMEHeader()
+	if err != nil {
+		sendSourceStatus(conn, proto, "400 Bad Request")
+		conn.Close()
+		return
+	}
+	user, password, ok := parseBasicAuth(headers.Get("Authorization"))
+	if !ok || user != sourceUser || subtle.ConstantTimeCompare([]byte(password), []byte(s.sourcePassword)) != 1 {
+		s.messageChan <- fmt.Sprintf("source %s: rejected credentials", remoteAddr)
+		sendSourceStatus(conn, proto, "401 Unauthorized")
+		conn.Close()
+		return
+	}
+	stationNum, ok := s.findMount(strings.TrimPrefix(fields[1], "/"))
+	if !ok {
+		sendSourceStatus(conn, proto, "404 Not Found")
+		conn.Close()
+		return
+	}
+	title := headers.Get("Ice-Name")
+	if title == "" {
+		title = config.DefaultLiveTitle
+	}
+	stream := &sourceStream{
+		reader:     reader,
+		conn:       conn,
+		stationNum: stationNum,
+		msgChan:    s.messageChan,
+		attached:   make(chan bool, 1),
+	}
+	live := radio.CreateStreamSource(stream, title)
+	err = s.radio.AttachLiveSource(stationNum, live)
+	if err != nil {
+		stream.attached <- false
+		sendSourceStatus(conn, proto, "403 Forbidden")
+		live.Quit()
+		return
+	}
+	stream.attached <- true
+	if headers.Get("Expect") == "100-continue" {
+		err = sendSourceStatus(conn, proto, "100 Continue")
+	} else {
+		err = sendSourceStatus(conn, proto, "200 OK")
+	}
+	if err != nil {
+		return
+	}
+	s.messageChan <- fmt.Sprintf("source %s: streaming %s into station %d", remoteAddr, title, stationNum)
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/IMaloney/snowcast/pkg/utils"
+)
+
+// handleWatchRequest starts sending the client the song changes of stations without streaming them. Either every
+// station is watched or none are. The client is told every station it watches and the song playing on it
+func (s *Server) handleWatchRequest(connAddr net.Addr, stationNums []uint16) error {
+	s.connectionsMutex.RLock()
+	conn := s.connections[connAddr]
+	s.connectionsMutex.RUnlock()
+	for _, stationNum := range stationNums {
+		if _, err := s.radio.GetSongName(stationNum); err != nil {
+			return conn.sendWatchDenied(err.Error())
+		}
+	}
+	for _, stationNum := range stationNums {
+		if !conn.startWatching(stationNum) {
+			// watching a station twice changes nothing
+			continue
+		}
+		_, err := s.radio.WatchStation(stationNum, conn.addr, conn.watcher)
+		if err != nil {
+			// the station went away since it was checked
+			conn.stopWatching(stationNum)
+			continue
+		}
+		conn.tunedIn.Store(true)
+	}
+	return s.sendWatching(conn)
+}
+
+// handleUnwatchRequest stops sending the client the song changes of stations. Either every station is unwatched or
+// none are. The client is told the stations it still watches
+func (s *Server) handleUnwatchRequest(connAddr net.Addr, stationNums []uint16) error {
+	s.connectionsMutex.RLock()
+	conn := s.connections[connAddr]
+	s.connectionsMutex.RUnlock()
+	for _, stationNum := range stationNums {
+		if !conn.isWatching(stationNum) {
+			return conn.sendWatchDenied(fmt.Sprintf("You are not watching station %d", stationNum))
+		}
+	}
+	for _, stationNum := range stationNums {
+		if conn.stopWatching(stationNum) {
+			s.radio.UnwatchStation(stationNum, conn.addr)
+		}
+	}
+	return s.sendWatching(conn)
+}
+
+// sendWatching tells the client every station it watches and the song playing on it
+func (s *Server) sendWatching(conn *connection) error {
+	stations := make([]utils.WatchedStation, 0)
+	for _, stationNum := range conn.getWatching() {
+		songName, err := s.radio.GetSongName(stationNum)
+		if err != nil {
+			continue
+		}
+		stations = append(stations, utils.WatchedStation{Station: stationNum, Song: songName})
+	}
+	return conn.sendWatching(stations)
+}
+
+// unwatchAll stops every station the client watches sending it song changes
+func (s *Server) unwatchAll(conn *connection) {
+	for _, stationNum := range conn.getWatching() {
+		conn.stopWatching(stationNum)
+		s.radio.UnwatchStation(stationNum, conn.addr)
+	}
+}
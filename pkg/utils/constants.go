@@ -8,6 +8,19 @@ const (
 	Hello CommandType = iota
 	SetStation
 	GetStationSongs
+	GetPlaylist
+	GetShows
+	Admin
+	ListStations
+	GetAllStationSongs
+	SearchSongs
+	RequestSong
+	VoteSkip
+	ChatMessage
+	Watch
+	Unwatch
+	StopListening
+	Ping
 )
 
 const (
@@ -17,10 +30,79 @@ const (
 	SongsList
 	NewStation
 	StationShutdown
+	ShowInfo
+	Interstitial
+	AdminReply
+	StationPaused
+	StationResumed
+	StationDraining
+	StationList
+	AllStationSongs
+	SearchResults
+	RequestQueued
+	RequestPlaying
+	RequestDenied
+	SkipVotes
+	VoteDenied
+	ChatReply
+	ChatDenied
+	WatchedSong
+	Watching
+	WatchDenied
+	StoppedListening
+	StopDenied
+	Pong
+	ServerShutdown
 )
 
 const (
 	SONGCHUNK = 256
 	SLEEPTIME = 1000
 	BUFFSIZE  = 4096
+	// number of chunks a live feed can buffer before the feed is held up
+	LIVEBUFFER = 64
+	// time in milliseconds without live data before a station falls back to its playlist
+	LIVETIMEOUT = 5000
+	// time in milliseconds between rescans of a station directory
+	RESCANTIME = 30000
+	// time in milliseconds between snapshots of station positions
+	STATETIME = 10000
+	// time in milliseconds between checks of the station schedule
+	SCHEDULETIME = 15000
+	// time in milliseconds a draining live station stays on before it is removed
+	DRAINTIME = 30000
+	// time in milliseconds between countdown notices of a draining station
+	DRAINNOTICE = 10000
+	// time in milliseconds between attempts to play songs that failed
+	RETRYTIME = 60000
+	// number of station warnings held until the server logs them
+	WARNBUFFER = 16
+	// most matches a song search returns
+	SEARCHLIMIT = 100
+	// most upcoming songs a playlist preview returns
+	PLAYLISTLIMIT = 100
+	// time in milliseconds a client waits between song requests
+	REQUESTTIME = 30000
+	// most song requests waiting on a station
+	REQUESTQUEUE = 10
+	// fraction of the listeners of a station that have to vote to skip a song
+	SKIPFRACTION = 0.5
+	// most characters in a chat message
+	CHATLENGTH = 200
+	// time in milliseconds a client waits between chat messages
+	CHATTIME = 1000
+	// number of song changes a watching client can fall behind before it misses some
+	WATCHBUFFER = 16
+	// time in milliseconds between the heartbeats of a client
+	HEARTBEATTIME = 5000
+	// heartbeats in a row that can go unanswered before a connection is dropped
+	HEARTBEATMISSES = 3
+	// time in milliseconds a client has to say hello after connecting
+	HELLOTIMEOUT = 5000
+	// time in milliseconds a client has to tune in to or watch a station after saying hello
+	IDLETIMEOUT = 300000
+	// attempts a client makes to reconnect to a server that said it would be back
+	RECONNECTTRIES = 5
+	// time in milliseconds between attempts to reconnect
+	RECONNECTTIME = 2000
 )
@@ -3,6 +3,8 @@ package utils
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
+	"math"
 	"strings"
 )
 
@@ -17,6 +19,11 @@ type announce struct {
 	songNameSize uint8
 }
 
+type interstitial struct {
+	replyType    uint8
+	clipNameSize uint8
+}
+
 type invalidCommand struct {
 	replyType       uint8
 	replyStringSize uint8
@@ -39,11 +46,214 @@ type stationShutdown struct {
 	numStations uint16
 }
 
+type showInfo struct {
+	replyType       uint8
+	currentShowSize uint8
+	nextShowSize    uint8
+	// minutes from now until the next show starts, which is at most a week away
+	nextShowIn uint16
+}
+
+type adminReply struct {
+	replyType uint8
+	replySize uint16
+}
+
+type stationPaused struct {
+	replyType uint8
+	station   uint16
+}
+
+type stationResumed struct {
+	replyType uint8
+	station   uint16
+}
+
+type stationDraining struct {
+	replyType   uint8
+	station     uint16
+	secondsLeft uint16
+}
+
+type stationList struct {
+	replyType   uint8
+	numStations uint16
+}
+
+// each station in a station list is followed by its name, description and current song
+type stationListEntry struct {
+	station         uint16
+	nameSize        uint8
+	descriptionSize uint8
+	songNameSize    uint8
+	listeners       uint32
+}
+
+// StationEntry describes one station of a station list
+type StationEntry struct {
+	Station     uint16
+	Name        string
+	Description string
+	Song        string
+	Listeners   uint32
+}
+
+type allStationSongs struct {
+	replyType   uint8
+	numStations uint16
+	// bytes of station entries after this header
+	length uint32
+}
+
+// each station is followed by its name, the song playing and its comma separated songs
+type stationSongsEntry struct {
+	station        uint16
+	nameSize       uint8
+	nowPlayingSize uint8
+	songsLength    uint16
+}
+
+// StationSongs holds the songs of one station of an all station songs reply
+type StationSongs struct {
+	Station    uint16
+	Name       string
+	NowPlaying string
+	Songs      []string
+}
+
+type searchResults struct {
+	replyType  uint8
+	numMatches uint16
+	// bytes of matches after this header
+	length uint32
+}
+
+// each match is followed by the song name and its comma separated tags
+type searchResultEntry struct {
+	station      uint16
+	position     uint16
+	onAir        uint8
+	songNameSize uint8
+	tagsSize     uint8
+}
+
+// SongMatch is one song of a search results reply
+type SongMatch struct {
+	Station  uint16
+	Position uint16
+	OnAir    bool
+	Song     string
+	Tags     []string
+}
+
+type requestQueued struct {
+	replyType    uint8
+	station      uint16
+	position     uint16
+	songNameSize uint8
+}
+
+type requestPlaying struct {
+	replyType    uint8
+	station      uint16
+	songNameSize uint8
+}
+
+type requestDenied struct {
+	replyType  uint8
+	reasonSize uint8
+}
+
+type skipVotes struct {
+	replyType uint8
+	station   uint16
+	votes     uint16
+	needed    uint16
+}
+
+type voteDenied struct {
+	replyType  uint8
+	reasonSize uint8
+}
+
+type chatReply struct {
+	replyType    uint8
+	station      uint16
+	nicknameSize uint8
+	textSize     uint8
+}
+
+type chatDenied struct {
+	replyType  uint8
+	reasonSize uint8
+}
+
+type watchedSong struct {
+	replyType    uint8
+	station      uint16
+	songNameSize uint8
+}
+
+type watching struct {
+	replyType   uint8
+	numStations uint16
+	// bytes of watched stations after this header
+	length uint32
+}
+
+// each watched station is followed by the name of the song playing on it
+type watchingEntry struct {
+	station      uint16
+	songNameSize uint8
+}
+
+// WatchedStation is a station a client watches and the song playing on it
+type WatchedStation struct {
+	Station uint16
+	Song    string
+}
+
+type watchDenied struct {
+	replyType  uint8
+	reasonSize uint8
+}
+
+type stoppedListening struct {
+	replyType uint8
+	station   uint16
+}
+
+type stopDenied struct {
+	replyType  uint8
+	reasonSize uint8
+}
+
+type pong struct {
+	replyType uint8
+	// milliseconds the server expects between heartbeats
+	interval uint32
+}
+
+type serverShutdown struct {
+	replyType uint8
+	// seconds until the server is back. 0 if it isn't coming back
+	reconnectAfter uint16
+	reasonSize     uint8
+}
+
 // client commands
 type hello struct {
 	commandType uint8
 	udpPort     uint16
 }
+
+// extra credit clients can follow hello with the nickname they chat with
+type namedHello struct {
+	commandType  uint8
+	udpPort      uint16
+	nicknameSize uint8
+}
+
 type setStation struct {
 	commandType   uint8
 	stationNumber uint16
@@ -54,6 +264,65 @@ type getStationSongs struct {
 	stationNumber uint16
 }
 
+type getPlaylist struct {
+	commandType   uint8
+	stationNumber uint16
+	numSongs      uint16
+}
+
+type getShows struct {
+	commandType   uint8
+	stationNumber uint16
+}
+
+type listStations struct {
+	commandType uint8
+}
+
+type getAllStationSongs struct {
+	commandType uint8
+}
+
+type searchSongs struct {
+	commandType uint8
+	querySize   uint8
+}
+
+type requestSong struct {
+	commandType   uint8
+	stationNumber uint16
+	songNameSize  uint8
+}
+
+type voteSkip struct {
+	commandType uint8
+}
+
+type chatMessage struct {
+	commandType uint8
+	textSize    uint8
+}
+
+type stopListening struct {
+	commandType uint8
+}
+
+type ping struct {
+	commandType uint8
+}
+
+// watch and unwatch are followed by the station numbers
+type watch struct {
+	commandType uint8
+	numStations uint8
+}
+
+type adminCommand struct {
+	commandType  uint8
+	passwordSize uint8
+	commandSize  uint16
+}
+
 // CreateWelcomeMessage creates the welcome message that the server responses with
 func CreateWelcomeMessage(numStations uint16) ([]byte, error) {
 	buffer := new(bytes.Buffer)
@@ -88,6 +357,25 @@ func CreateAnnounceMessage(songName string) ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
+// CreateInterstitialMessage creates the message announcing a clip played between songs
+func CreateInterstitialMessage(clipName string) ([]byte, error) {
+	clip := []byte(clipName)
+	buffer := new(bytes.Buffer)
+	message := interstitial{
+		replyType:    uint8(Interstitial),
+		clipNameSize: uint8(len(clip)),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.WriteString(clipName)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
 // CreateInvalidCommandMessage creates the Invalid Command Message the server responds with
 func CreateInvalidCommandMessage(reply string) ([]byte, error) {
 	r := []byte(reply)
@@ -120,6 +408,25 @@ func CreateHelloMessage(udpPort uint16) ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
+// CreateNamedHelloMessage creates a hello message that carries the nickname the client chats with
+func CreateNamedHelloMessage(udpPort uint16, nickname string) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := namedHello{
+		commandType:  uint8(Hello),
+		udpPort:      udpPort,
+		nicknameSize: uint8(len(nickname)),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.WriteString(nickname)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
 func CreateSetStationMessage(num uint16) ([]byte, error) {
 	buffer := new(bytes.Buffer)
 	message := setStation{
@@ -146,9 +453,39 @@ func CreateGetStationSongsMessage(num uint16) ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
+func CreateGetPlaylistMessage(num, numSongs uint16) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := getPlaylist{
+		commandType:   uint8(GetPlaylist),
+		stationNumber: num,
+		numSongs:      numSongs,
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func CreateGetShowsMessage(num uint16) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := getShows{
+		commandType:   uint8(GetShows),
+		stationNumber: num,
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
 func CreateSongsListMessage(songs []string) ([]byte, error) {
 	buffer := new(bytes.Buffer)
 	songList := strings.Join(songs, ",")
+	if len(songList) > math.MaxUint16 {
+		return nil, fmt.Errorf("Could not create message for %d songs. The list is %d bytes, at most %d fit", len(songs), len(songList), math.MaxUint16)
+	}
 	message := songsList{
 		replyType:    uint8(SongsList),
 		stringLength: uint16(len(songList)),
@@ -191,3 +528,799 @@ func CreateShutdownStationMessage(currStation, numStations uint16) ([]byte, erro
 	}
 	return buffer.Bytes(), nil
 }
+
+// CreateShowInfoMessage creates the message with the current and next show of a station. An empty current show
+// means regular programming and an empty next show means nothing is scheduled
+func CreateShowInfoMessage(currentShow, nextShow string, nextShowIn uint16) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := showInfo{
+		replyType:       uint8(ShowInfo),
+		currentShowSize: uint8(len(currentShow)),
+		nextShowSize:    uint8(len(nextShow)),
+		nextShowIn:      nextShowIn,
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.WriteString(currentShow + nextShow)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateAdminCommandMessage creates the message running a server command with the admin password
+func CreateAdminCommandMessage(password, command string) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := adminCommand{
+		commandType:  uint8(Admin),
+		passwordSize: uint8(len(password)),
+		commandSize:  uint16(len(command)),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.WriteString(password + command)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateAdminReplyMessage creates the message with the result of an admin command
+func CreateAdminReplyMessage(reply string) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := adminReply{
+		replyType: uint8(AdminReply),
+		replySize: uint16(len(reply)),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.WriteString(reply)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateStationPausedMessage creates the message telling listeners their station paused
+func CreateStationPausedMessage(station uint16) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := stationPaused{
+		replyType: uint8(StationPaused),
+		station:   station,
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateStationResumedMessage creates the message telling listeners their station resumed
+func CreateStationResumedMessage(station uint16) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := stationResumed{
+		replyType: uint8(StationResumed),
+		station:   station,
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateStationDrainingMessage creates the message counting down to a station shutting down
+func CreateStationDrainingMessage(station, secondsLeft uint16) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := stationDraining{
+		replyType:   uint8(StationDraining),
+		station:     station,
+		secondsLeft: secondsLeft,
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateListStationsMessage creates the message asking for every station on the server
+func CreateListStationsMessage() ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := listStations{
+		commandType: uint8(ListStations),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateStationListMessage creates the message describing every station on the server
+func CreateStationListMessage(stations []StationEntry) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := stationList{
+		replyType:   uint8(StationList),
+		numStations: uint16(len(stations)),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	for _, station := range stations {
+		entry := stationListEntry{
+			station:         station.Station,
+			nameSize:        uint8(len(station.Name)),
+			descriptionSize: uint8(len(station.Description)),
+			songNameSize:    uint8(len(station.Song)),
+			listeners:       station.Listeners,
+		}
+		err = binary.Write(buffer, binary.BigEndian, entry)
+		if err != nil {
+			return nil, err
+		}
+		_, err = buffer.WriteString(station.Name + station.Description + station.Song)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buffer.Bytes(), nil
+}
+
+// ParseStationListMessage reads the stations out of a station list message
+func ParseStationListMessage(buffer []byte) ([]StationEntry, error) {
+	if len(buffer) < 3 || ReplyType(buffer[0]) != StationList {
+		return nil, fmt.Errorf("not a station list")
+	}
+	numStations := int(binary.BigEndian.Uint16(buffer[1:3]))
+	stations := make([]StationEntry, 0)
+	offset := 3
+	for idx := 0; idx < numStations; idx++ {
+		if offset+9 > len(buffer) {
+			return nil, fmt.Errorf("station list is cut short")
+		}
+		nameSize := int(buffer[offset+2])
+		descriptionSize := int(buffer[offset+3])
+		songNameSize := int(buffer[offset+4])
+		station := StationEntry{
+			Station:   binary.BigEndian.Uint16(buffer[offset : offset+2]),
+			Listeners: binary.BigEndian.Uint32(buffer[offset+5 : offset+9]),
+		}
+		offset += 9
+		if offset+nameSize+descriptionSize+songNameSize > len(buffer) {
+			return nil, fmt.Errorf("station list is cut short")
+		}
+		station.Name = string(buffer[offset : offset+nameSize])
+		offset += nameSize
+		station.Description = string(buffer[offset : offset+descriptionSize])
+		offset += descriptionSize
+		station.Song = string(buffer[offset : offset+songNameSize])
+		offset += songNameSize
+		stations = append(stations, station)
+	}
+	return stations, nil
+}
+
+// CreateGetAllStationSongsMessage creates the message asking for the songs of every station
+func CreateGetAllStationSongsMessage() ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := getAllStationSongs{
+		commandType: uint8(GetAllStationSongs),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateAllStationSongsMessage creates the message with the songs and song playing of every station
+func CreateAllStationSongsMessage(stations []StationSongs) ([]byte, error) {
+	entries := new(bytes.Buffer)
+	for _, station := range stations {
+		songs := strings.Join(station.Songs, ",")
+		entry := stationSongsEntry{
+			station:        station.Station,
+			nameSize:       uint8(len(station.Name)),
+			nowPlayingSize: uint8(len(station.NowPlaying)),
+			songsLength:    uint16(len(songs)),
+		}
+		err := binary.Write(entries, binary.BigEndian, entry)
+		if err != nil {
+			return nil, err
+		}
+		_, err = entries.WriteString(station.Name + station.NowPlaying + songs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	buffer := new(bytes.Buffer)
+	message := allStationSongs{
+		replyType:   uint8(AllStationSongs),
+		numStations: uint16(len(stations)),
+		length:      uint32(entries.Len()),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.Write(entries.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// AllStationSongsLength returns the length of the all station songs message starting with the header
+func AllStationSongsLength(header []byte) int {
+	return 7 + int(binary.BigEndian.Uint32(header[3:7]))
+}
+
+// ParseAllStationSongsMessage reads the stations out of an all station songs message
+func ParseAllStationSongsMessage(buffer []byte) ([]StationSongs, error) {
+	if len(buffer) < 7 || ReplyType(buffer[0]) != AllStationSongs {
+		return nil, fmt.Errorf("not a list of station songs")
+	}
+	if len(buffer) < AllStationSongsLength(buffer) {
+		return nil, fmt.Errorf("list of station songs is cut short")
+	}
+	numStations := int(binary.BigEndian.Uint16(buffer[1:3]))
+	stations := make([]StationSongs, 0)
+	offset := 7
+	for idx := 0; idx < numStations; idx++ {
+		if offset+6 > len(buffer) {
+			return nil, fmt.Errorf("list of station songs is cut short")
+		}
+		nameSize := int(buffer[offset+2])
+		nowPlayingSize := int(buffer[offset+3])
+		songsLength := int(binary.BigEndian.Uint16(buffer[offset+4 : offset+6]))
+		station := StationSongs{
+			Station: binary.BigEndian.Uint16(buffer[offset : offset+2]),
+			Songs:   make([]string, 0),
+		}
+		offset += 6
+		if offset+nameSize+nowPlayingSize+songsLength > len(buffer) {
+			return nil, fmt.Errorf("list of station songs is cut short")
+		}
+		station.Name = string(buffer[offset : offset+nameSize])
+		offset += nameSize
+		station.NowPlaying = string(buffer[offset : offset+nowPlayingSize])
+		offset += nowPlayingSize
+		if songsLength > 0 {
+			station.Songs = strings.Split(string(buffer[offset:offset+songsLength]), ",")
+		}
+		offset += songsLength
+		stations = append(stations, station)
+	}
+	return stations, nil
+}
+
+// CreateSearchSongsMessage creates the message searching every station for songs matching the query
+func CreateSearchSongsMessage(query string) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := searchSongs{
+		commandType: uint8(SearchSongs),
+		querySize:   uint8(len(query)),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.WriteString(query)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateSearchResultsMessage creates the message with the songs matching a search
+func CreateSearchResultsMessage(matches []SongMatch) ([]byte, error) {
+	entries := new(bytes.Buffer)
+	for _, match := range matches {
+		tags := strings.Join(match.Tags, ",")
+		entry := searchResultEntry{
+			station:      match.Station,
+			position:     match.Position,
+			songNameSize: uint8(len(match.Song)),
+			tagsSize:     uint8(len(tags)),
+		}
+		if match.OnAir {
+			entry.onAir = 1
+		}
+		err := binary.Write(entries, binary.BigEndian, entry)
+		if err != nil {
+			return nil, err
+		}
+		_, err = entries.WriteString(match.Song + tags)
+		if err != nil {
+			return nil, err
+		}
+	}
+	buffer := new(bytes.Buffer)
+	message := searchResults{
+		replyType:  uint8(SearchResults),
+		numMatches: uint16(len(matches)),
+		length:     uint32(entries.Len()),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.Write(entries.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// SearchResultsLength returns the length of the search results message starting with the header
+func SearchResultsLength(header []byte) int {
+	return 7 + int(binary.BigEndian.Uint32(header[3:7]))
+}
+
+// ParseSearchResultsMessage reads the matches out of a search results message
+func ParseSearchResultsMessage(buffer []byte) ([]SongMatch, error) {
+	if len(buffer) < 7 || ReplyType(buffer[0]) != SearchResults {
+		return nil, fmt.Errorf("not a list of search results")
+	}
+	if len(buffer) < SearchResultsLength(buffer) {
+		return nil, fmt.Errorf("list of search results is cut short")
+	}
+	numMatches := int(binary.BigEndian.Uint16(buffer[1:3]))
+	matches := make([]SongMatch, 0)
+	offset := 7
+	for idx := 0; idx < numMatches; idx++ {
+		if offset+7 > len(buffer) {
+			return nil, fmt.Errorf("list of search results is cut short")
+		}
+		songNameSize := int(buffer[offset+5])
+		tagsSize := int(buffer[offset+6])
+		match := SongMatch{
+			Station:  binary.BigEndian.Uint16(buffer[offset : offset+2]),
+			Position: binary.BigEndian.Uint16(buffer[offset+2 : offset+4]),
+			OnAir:    buffer[offset+4] == 1,
+			Tags:     make([]string, 0),
+		}
+		offset += 7
+		if offset+songNameSize+tagsSize > len(buffer) {
+			return nil, fmt.Errorf("list of search results is cut short")
+		}
+		match.Song = string(buffer[offset : offset+songNameSize])
+		offset += songNameSize
+		if tagsSize > 0 {
+			match.Tags = strings.Split(string(buffer[offset:offset+tagsSize]), ",")
+		}
+		offset += tagsSize
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+// CreateRequestSongMessage creates the message asking a station to play one of its songs next
+func CreateRequestSongMessage(stationNum uint16, songName string) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := requestSong{
+		commandType:   uint8(RequestSong),
+		stationNumber: stationNum,
+		songNameSize:  uint8(len(songName)),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.WriteString(songName)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateRequestQueuedMessage creates the message telling a listener their requested song is queued and its place in the queue
+func CreateRequestQueuedMessage(station, position uint16, songName string) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := requestQueued{
+		replyType:    uint8(RequestQueued),
+		station:      station,
+		position:     position,
+		songNameSize: uint8(len(songName)),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.WriteString(songName)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateRequestPlayingMessage creates the message telling a listener their requested song started
+func CreateRequestPlayingMessage(station uint16, songName string) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := requestPlaying{
+		replyType:    uint8(RequestPlaying),
+		station:      station,
+		songNameSize: uint8(len(songName)),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.WriteString(songName)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateRequestDeniedMessage creates the message telling a listener why their song request wasn't queued
+func CreateRequestDeniedMessage(reason string) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := requestDenied{
+		replyType:  uint8(RequestDenied),
+		reasonSize: uint8(len(reason)),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.WriteString(reason)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateVoteSkipMessage creates the message voting to skip the song playing on the station the client listens to
+func CreateVoteSkipMessage() ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := voteSkip{
+		commandType: uint8(VoteSkip),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateSkipVotesMessage creates the message with the votes to skip the song playing on a station and the votes needed
+func CreateSkipVotesMessage(station, votes, needed uint16) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := skipVotes{
+		replyType: uint8(SkipVotes),
+		station:   station,
+		votes:     votes,
+		needed:    needed,
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateVoteDeniedMessage creates the message telling a listener why their vote to skip wasn't counted
+func CreateVoteDeniedMessage(reason string) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := voteDenied{
+		replyType:  uint8(VoteDenied),
+		reasonSize: uint8(len(reason)),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.WriteString(reason)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateChatMessage creates the message sending a line of chat to the listeners of the client's station
+func CreateChatMessage(text string) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := chatMessage{
+		commandType: uint8(ChatMessage),
+		textSize:    uint8(len(text)),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.WriteString(text)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateChatReplyMessage creates the message relaying a line of chat to the listeners of a station
+func CreateChatReplyMessage(station uint16, nickname, text string) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := chatReply{
+		replyType:    uint8(ChatReply),
+		station:      station,
+		nicknameSize: uint8(len(nickname)),
+		textSize:     uint8(len(text)),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.WriteString(nickname + text)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateChatDeniedMessage creates the message telling a client why their chat message wasn't sent
+func CreateChatDeniedMessage(reason string) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := chatDenied{
+		replyType:  uint8(ChatDenied),
+		reasonSize: uint8(len(reason)),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.WriteString(reason)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateWatchMessage creates the message asking to follow the song changes of stations without listening to them
+func CreateWatchMessage(stationNums []uint16) ([]byte, error) {
+	return createWatchMessage(Watch, stationNums)
+}
+
+// CreateUnwatchMessage creates the message asking to stop following the song changes of stations
+func CreateUnwatchMessage(stationNums []uint16) ([]byte, error) {
+	return createWatchMessage(Unwatch, stationNums)
+}
+
+// createWatchMessage creates a watch or unwatch message for the stations
+func createWatchMessage(commandType CommandType, stationNums []uint16) ([]byte, error) {
+	if len(stationNums) > 255 {
+		return nil, fmt.Errorf("Could not create message for %d stations. At most 255 stations fit", len(stationNums))
+	}
+	buffer := new(bytes.Buffer)
+	message := watch{
+		commandType: uint8(commandType),
+		numStations: uint8(len(stationNums)),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	err = binary.Write(buffer, binary.BigEndian, stationNums)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// ParseWatchMessage returns the station numbers of a watch or unwatch message
+func ParseWatchMessage(buffer []byte) ([]uint16, error) {
+	if len(buffer) < 2 {
+		return nil, fmt.Errorf("not a list of stations to watch")
+	}
+	numStations := int(buffer[1])
+	if len(buffer) < 2+2*numStations {
+		return nil, fmt.Errorf("list of stations to watch is cut short")
+	}
+	stationNums := make([]uint16, 0)
+	for idx := 0; idx < numStations; idx++ {
+		stationNums = append(stationNums, binary.BigEndian.Uint16(buffer[2+2*idx:4+2*idx]))
+	}
+	return stationNums, nil
+}
+
+// CreateWatchedSongMessage creates the message with the song playing on a watched station
+func CreateWatchedSongMessage(station uint16, songName string) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := watchedSong{
+		replyType:    uint8(WatchedSong),
+		station:      station,
+		songNameSize: uint8(len(songName)),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.WriteString(songName)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateWatchingMessage creates the message with every station a client watches and the song playing on it
+func CreateWatchingMessage(stations []WatchedStation) ([]byte, error) {
+	entries := new(bytes.Buffer)
+	for _, station := range stations {
+		entry := watchingEntry{
+			station:      station.Station,
+			songNameSize: uint8(len(station.Song)),
+		}
+		err := binary.Write(entries, binary.BigEndian, entry)
+		if err != nil {
+			return nil, err
+		}
+		_, err = entries.WriteString(station.Song)
+		if err != nil {
+			return nil, err
+		}
+	}
+	buffer := new(bytes.Buffer)
+	message := watching{
+		replyType:   uint8(Watching),
+		numStations: uint16(len(stations)),
+		length:      uint32(entries.Len()),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.Write(entries.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// WatchingLength returns the length of the watching message starting with the header
+func WatchingLength(header []byte) int {
+	return 7 + int(binary.BigEndian.Uint32(header[3:7]))
+}
+
+// ParseWatchingMessage reads the watched stations out of a watching message
+func ParseWatchingMessage(buffer []byte) ([]WatchedStation, error) {
+	if len(buffer) < 7 || ReplyType(buffer[0]) != Watching {
+		return nil, fmt.Errorf("not a list of watched stations")
+	}
+	if len(buffer) < WatchingLength(buffer) {
+		return nil, fmt.Errorf("list of watched stations is cut short")
+	}
+	numStations := int(binary.BigEndian.Uint16(buffer[1:3]))
+	stations := make([]WatchedStation, 0)
+	offset := 7
+	for idx := 0; idx < numStations; idx++ {
+		if offset+3 > len(buffer) {
+			return nil, fmt.Errorf("list of watched stations is cut short")
+		}
+		station := binary.BigEndian.Uint16(buffer[offset : offset+2])
+		songNameSize := int(buffer[offset+2])
+		offset += 3
+		if offset+songNameSize > len(buffer) {
+			return nil, fmt.Errorf("list of watched stations is cut short")
+		}
+		stations = append(stations, WatchedStation{Station: station, Song: string(buffer[offset : offset+songNameSize])})
+		offset += songNameSize
+	}
+	return stations, nil
+}
+
+// CreateWatchDeniedMessage creates the message telling a client why it couldn't watch or unwatch a station
+func CreateWatchDeniedMessage(reason string) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := watchDenied{
+		replyType:  uint8(WatchDenied),
+		reasonSize: uint8(len(reason)),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.WriteString(reason)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateStopListeningMessage creates the message asking to stop listening to a station while staying connected
+func CreateStopListeningMessage() ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := stopListening{
+		commandType: uint8(StopListening),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateStoppedListeningMessage creates the message telling a client it stopped listening to a station
+func CreateStoppedListeningMessage(station uint16) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := stoppedListening{
+		replyType: uint8(StoppedListening),
+		station:   station,
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateStopDeniedMessage creates the message telling a client why it couldn't stop listening
+func CreateStopDeniedMessage(reason string) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := stopDenied{
+		replyType:  uint8(StopDenied),
+		reasonSize: uint8(len(reason)),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.WriteString(reason)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreatePingMessage creates the heartbeat a client sends to show it is still there
+func CreatePingMessage() ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := ping{
+		commandType: uint8(Ping),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreatePongMessage creates the server's answer to a heartbeat. It carries the milliseconds the server expects
+// between heartbeats so clients can keep up with it
+func CreatePongMessage(interval uint32) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := pong{
+		replyType: uint8(Pong),
+		interval:  interval,
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CreateServerShutdownMessage creates the message telling a client why the server is going away and how many
+// seconds until it is back. 0 seconds means it isn't coming back
+func CreateServerShutdownMessage(reason string, reconnectAfter uint16) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	message := serverShutdown{
+		replyType:      uint8(ServerShutdown),
+		reconnectAfter: reconnectAfter,
+		reasonSize:     uint8(len(reason)),
+	}
+	err := binary.Write(buffer, binary.BigEndian, message)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buffer.WriteString(reason)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"encoding/binary"
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -114,6 +115,27 @@ func TestCreateGetSongsMessage(t *testing.T) {
 	}
 }
 
+func TestCreateGetPlaylistMessage(t *testing.T) {
+	station := uint16(453)
+	numSongs := uint16(12)
+	buffer, err := CreateGetPlaylistMessage(station, numSongs)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	commandType := CommandType(buffer[0])
+	num := binary.BigEndian.Uint16(buffer[1:3])
+	songs := binary.BigEndian.Uint16(buffer[3:5])
+	if commandType != GetPlaylist {
+		t.Errorf("expected: %d == %d, received: false", commandType, GetPlaylist)
+	}
+	if station != num {
+		t.Errorf("expected: %d == %d, received: false", station, num)
+	}
+	if numSongs != songs {
+		t.Errorf("expected: %d == %d, received: false", numSongs, songs)
+	}
+}
+
 func TestCreateSongsListMessage(t *testing.T) {
 	songs := []string{"greetings", "its", "wednesday", "my", "dudes"}
 	str := strings.Join(songs, ",")
@@ -133,6 +155,11 @@ func TestCreateSongsListMessage(t *testing.T) {
 	if str != s {
 		t.Errorf("expected %s == %s, received: false", str, s)
 	}
+	// a list too long for its length field is refused
+	_, err = CreateSongsListMessage([]string{strings.Repeat("a", 1<<16)})
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
 }
 
 func TestCreateNewStationMessage(t *testing.T) {
@@ -176,3 +203,699 @@ func TestCreateShutdownStationMessage(t *testing.T) {
 		t.Errorf("expected: %d == %d, received: false", numStations, nums)
 	}
 }
+
+func TestCreateGetShowsMessage(t *testing.T) {
+	stationNum := uint16(6)
+	buffer, err := CreateGetShowsMessage(stationNum)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	commandType := CommandType(buffer[0])
+	station := binary.BigEndian.Uint16(buffer[1:3])
+	if commandType != GetShows {
+		t.Errorf("expected: %d == %d, received: false", commandType, GetShows)
+	}
+	if station != stationNum {
+		t.Errorf("expected: %d == %d, received: false", station, stationNum)
+	}
+}
+
+func TestCreateShowInfoMessage(t *testing.T) {
+	current := "focus"
+	next := "upbeat"
+	in := uint16(3 * 24 * 60)
+	buffer, err := CreateShowInfoMessage(current, next, in)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	currentSize := int(buffer[1])
+	nextSize := int(buffer[2])
+	nextIn := binary.BigEndian.Uint16(buffer[3:5])
+	if replyType != ShowInfo {
+		t.Errorf("expected: %d == %d, received: false", replyType, ShowInfo)
+	}
+	if string(buffer[5:5+currentSize]) != current {
+		t.Errorf("expected: %s, received: %s", current, string(buffer[5:5+currentSize]))
+	}
+	if string(buffer[5+currentSize:5+currentSize+nextSize]) != next {
+		t.Errorf("expected: %s, received: %s", next, string(buffer[5+currentSize:5+currentSize+nextSize]))
+	}
+	if nextIn != in {
+		t.Errorf("expected: %d, received: %d", in, nextIn)
+	}
+}
+
+func TestCreateInterstitialMessage(t *testing.T) {
+	n := "station-id.mp3"
+	buffer, err := CreateInterstitialMessage(n)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	clipLength := int(buffer[1])
+	clip := string(buffer[2 : 2+clipLength])
+	if replyType != Interstitial {
+		t.Errorf("expected: %d == %d, received: false", replyType, Interstitial)
+	}
+	if clip != n {
+		t.Errorf("expected: %s, received: %s", n, clip)
+	}
+}
+
+func TestCreateAdminCommandMessage(t *testing.T) {
+	password := "hackme"
+	command := "skip 2"
+	buffer, err := CreateAdminCommandMessage(password, command)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	commandType := CommandType(buffer[0])
+	passwordSize := int(buffer[1])
+	commandSize := int(binary.BigEndian.Uint16(buffer[2:4]))
+	if commandType != Admin {
+		t.Errorf("expected: %d == %d, received: false", commandType, Admin)
+	}
+	if string(buffer[4:4+passwordSize]) != password {
+		t.Errorf("expected: %s, received: %s", password, string(buffer[4:4+passwordSize]))
+	}
+	if string(buffer[4+passwordSize:4+passwordSize+commandSize]) != command {
+		t.Errorf("expected: %s, received: %s", command, string(buffer[4+passwordSize:4+passwordSize+commandSize]))
+	}
+}
+
+func TestCreateAdminReplyMessage(t *testing.T) {
+	reply := "skipped the song on station 2"
+	buffer, err := CreateAdminReplyMessage(reply)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	replySize := int(binary.BigEndian.Uint16(buffer[1:3]))
+	if replyType != AdminReply {
+		t.Errorf("expected: %d == %d, received: false", replyType, AdminReply)
+	}
+	if string(buffer[3:3+replySize]) != reply {
+		t.Errorf("expected: %s, received: %s", reply, string(buffer[3:3+replySize]))
+	}
+}
+
+func TestCreateStationPausedMessage(t *testing.T) {
+	currStation := uint16(3)
+	buffer, err := CreateStationPausedMessage(currStation)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	station := binary.BigEndian.Uint16(buffer[1:3])
+	if replyType != StationPaused {
+		t.Errorf("expected: %d == %d, received: false", replyType, StationPaused)
+	}
+	if station != currStation {
+		t.Errorf("expected: %d == %d, received: false", station, currStation)
+	}
+}
+
+func TestCreateStationResumedMessage(t *testing.T) {
+	currStation := uint16(3)
+	buffer, err := CreateStationResumedMessage(currStation)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	station := binary.BigEndian.Uint16(buffer[1:3])
+	if replyType != StationResumed {
+		t.Errorf("expected: %d == %d, received: false", replyType, StationResumed)
+	}
+	if station != currStation {
+		t.Errorf("expected: %d == %d, received: false", station, currStation)
+	}
+}
+
+func TestCreateStationDrainingMessage(t *testing.T) {
+	currStation := uint16(3)
+	seconds := uint16(42)
+	buffer, err := CreateStationDrainingMessage(currStation, seconds)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	station := binary.BigEndian.Uint16(buffer[1:3])
+	secondsLeft := binary.BigEndian.Uint16(buffer[3:5])
+	if replyType != StationDraining {
+		t.Errorf("expected: %d == %d, received: false", replyType, StationDraining)
+	}
+	if station != currStation {
+		t.Errorf("expected: %d == %d, received: false", station, currStation)
+	}
+	if secondsLeft != seconds {
+		t.Errorf("expected: %d == %d, received: false", secondsLeft, seconds)
+	}
+}
+
+func TestCreateListStationsMessage(t *testing.T) {
+	buffer, err := CreateListStationsMessage()
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	commandType := CommandType(buffer[0])
+	if commandType != ListStations {
+		t.Errorf("expected: %d == %d, received: false", commandType, ListStations)
+	}
+}
+
+func TestCreateStationListMessage(t *testing.T) {
+	stations := []StationEntry{
+		{Station: 0, Name: "focus", Description: "music to work to", Song: "mp3/tinyfile", Listeners: 3},
+		{Station: 4, Name: "morning", Song: "Live"},
+	}
+	buffer, err := CreateStationListMessage(stations)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	if replyType != StationList {
+		t.Errorf("expected: %d == %d, received: false", replyType, StationList)
+	}
+	parsed, err := ParseStationListMessage(buffer)
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	if len(parsed) != len(stations) {
+		t.Fatalf("expected: %d, received: %d", len(stations), len(parsed))
+	}
+	for idx := range stations {
+		if parsed[idx] != stations[idx] {
+			t.Errorf("expected: %v, received: %v", stations[idx], parsed[idx])
+		}
+	}
+	_, err = ParseStationListMessage(buffer[:len(buffer)-1])
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+}
+
+func TestCreateGetAllStationSongsMessage(t *testing.T) {
+	buffer, err := CreateGetAllStationSongsMessage()
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	commandType := CommandType(buffer[0])
+	if commandType != GetAllStationSongs {
+		t.Errorf("expected: %d == %d, received: false", commandType, GetAllStationSongs)
+	}
+}
+
+func TestCreateAllStationSongsMessage(t *testing.T) {
+	stations := []StationSongs{
+		{Station: 0, Name: "focus", NowPlaying: "b", Songs: []string{"a", "b", "c"}},
+		{Station: 3, Name: "morning", NowPlaying: "Live", Songs: []string{}},
+	}
+	buffer, err := CreateAllStationSongsMessage(stations)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	if replyType != AllStationSongs {
+		t.Errorf("expected: %d == %d, received: false", replyType, AllStationSongs)
+	}
+	if AllStationSongsLength(buffer) != len(buffer) {
+		t.Errorf("expected: %d, received: %d", len(buffer), AllStationSongsLength(buffer))
+	}
+	parsed, err := ParseAllStationSongsMessage(buffer)
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	if len(parsed) != len(stations) {
+		t.Fatalf("expected: %d, received: %d", len(stations), len(parsed))
+	}
+	for idx, station := range stations {
+		received := parsed[idx]
+		if received.Station != station.Station || received.Name != station.Name || received.NowPlaying != station.NowPlaying {
+			t.Errorf("expected: %v, received: %v", station, received)
+		}
+		if strings.Join(received.Songs, ",") != strings.Join(station.Songs, ",") {
+			t.Errorf("expected: %v, received: %v", station.Songs, received.Songs)
+		}
+	}
+	_, err = ParseAllStationSongsMessage(buffer[:len(buffer)-1])
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+}
+
+func TestCreateSearchSongsMessage(t *testing.T) {
+	query := "ice ice"
+	buffer, err := CreateSearchSongsMessage(query)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	commandType := CommandType(buffer[0])
+	querySize := int(buffer[1])
+	if commandType != SearchSongs {
+		t.Errorf("expected: %d == %d, received: false", commandType, SearchSongs)
+	}
+	if string(buffer[2:2+querySize]) != query {
+		t.Errorf("expected: %s, received: %s", query, string(buffer[2:2+querySize]))
+	}
+}
+
+func TestCreateSearchResultsMessage(t *testing.T) {
+	matches := []SongMatch{
+		{Station: 2, Position: 5, OnAir: true, Song: "mp3/gold.mp3", Tags: []string{"gold", "oldies"}},
+		{Station: 7, Position: 0, Song: "mp3/new.mp3", Tags: []string{}},
+	}
+	buffer, err := CreateSearchResultsMessage(matches)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	if replyType != SearchResults {
+		t.Errorf("expected: %d == %d, received: false", replyType, SearchResults)
+	}
+	if SearchResultsLength(buffer) != len(buffer) {
+		t.Errorf("expected: %d, received: %d", len(buffer), SearchResultsLength(buffer))
+	}
+	parsed, err := ParseSearchResultsMessage(buffer)
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	if len(parsed) != len(matches) {
+		t.Fatalf("expected: %d, received: %d", len(matches), len(parsed))
+	}
+	for idx, match := range matches {
+		received := parsed[idx]
+		if received.Station != match.Station || received.Position != match.Position || received.OnAir != match.OnAir || received.Song != match.Song {
+			t.Errorf("expected: %v, received: %v", match, received)
+		}
+		if strings.Join(received.Tags, ",") != strings.Join(match.Tags, ",") {
+			t.Errorf("expected: %v, received: %v", match.Tags, received.Tags)
+		}
+	}
+	_, err = ParseSearchResultsMessage(buffer[:len(buffer)-1])
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+}
+
+func TestCreateRequestSongMessage(t *testing.T) {
+	stationNum := uint16(4)
+	song := "mp3/tinyfile"
+	buffer, err := CreateRequestSongMessage(stationNum, song)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	commandType := CommandType(buffer[0])
+	station := binary.BigEndian.Uint16(buffer[1:3])
+	songSize := int(buffer[3])
+	if commandType != RequestSong {
+		t.Errorf("expected: %d == %d, received: false", commandType, RequestSong)
+	}
+	if station != stationNum {
+		t.Errorf("expected: %d == %d, received: false", station, stationNum)
+	}
+	if string(buffer[4:4+songSize]) != song {
+		t.Errorf("expected: %s, received: %s", song, string(buffer[4:4+songSize]))
+	}
+}
+
+func TestCreateRequestQueuedMessage(t *testing.T) {
+	stationNum := uint16(4)
+	place := uint16(2)
+	song := "mp3/tinyfile"
+	buffer, err := CreateRequestQueuedMessage(stationNum, place, song)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	station := binary.BigEndian.Uint16(buffer[1:3])
+	position := binary.BigEndian.Uint16(buffer[3:5])
+	songSize := int(buffer[5])
+	if replyType != RequestQueued {
+		t.Errorf("expected: %d == %d, received: false", replyType, RequestQueued)
+	}
+	if station != stationNum || position != place {
+		t.Errorf("expected: station %d position %d, received: station %d position %d", stationNum, place, station, position)
+	}
+	if string(buffer[6:6+songSize]) != song {
+		t.Errorf("expected: %s, received: %s", song, string(buffer[6:6+songSize]))
+	}
+}
+
+func TestCreateRequestPlayingMessage(t *testing.T) {
+	stationNum := uint16(4)
+	song := "mp3/tinyfile"
+	buffer, err := CreateRequestPlayingMessage(stationNum, song)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	station := binary.BigEndian.Uint16(buffer[1:3])
+	songSize := int(buffer[3])
+	if replyType != RequestPlaying {
+		t.Errorf("expected: %d == %d, received: false", replyType, RequestPlaying)
+	}
+	if station != stationNum {
+		t.Errorf("expected: %d == %d, received: false", station, stationNum)
+	}
+	if string(buffer[4:4+songSize]) != song {
+		t.Errorf("expected: %s, received: %s", song, string(buffer[4:4+songSize]))
+	}
+}
+
+func TestCreateRequestDeniedMessage(t *testing.T) {
+	reason := "Song mp3/x is not on the station"
+	buffer, err := CreateRequestDeniedMessage(reason)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	reasonSize := int(buffer[1])
+	if replyType != RequestDenied {
+		t.Errorf("expected: %d == %d, received: false", replyType, RequestDenied)
+	}
+	if string(buffer[2:2+reasonSize]) != reason {
+		t.Errorf("expected: %s, received: %s", reason, string(buffer[2:2+reasonSize]))
+	}
+}
+
+func TestCreateVoteSkipMessage(t *testing.T) {
+	buffer, err := CreateVoteSkipMessage()
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	commandType := CommandType(buffer[0])
+	if commandType != VoteSkip {
+		t.Errorf("expected: %d == %d, received: false", commandType, VoteSkip)
+	}
+}
+
+func TestCreateSkipVotesMessage(t *testing.T) {
+	currStation := uint16(3)
+	numVotes := uint16(2)
+	numNeeded := uint16(5)
+	buffer, err := CreateSkipVotesMessage(currStation, numVotes, numNeeded)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	station := binary.BigEndian.Uint16(buffer[1:3])
+	votes := binary.BigEndian.Uint16(buffer[3:5])
+	needed := binary.BigEndian.Uint16(buffer[5:7])
+	if replyType != SkipVotes {
+		t.Errorf("expected: %d == %d, received: false", replyType, SkipVotes)
+	}
+	if station != currStation || votes != numVotes || needed != numNeeded {
+		t.Errorf("expected: %d %d %d, received: %d %d %d", currStation, numVotes, numNeeded, station, votes, needed)
+	}
+}
+
+func TestCreateVoteDeniedMessage(t *testing.T) {
+	reason := "A live feed can't be skipped"
+	buffer, err := CreateVoteDeniedMessage(reason)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	reasonSize := int(buffer[1])
+	if replyType != VoteDenied {
+		t.Errorf("expected: %d == %d, received: false", replyType, VoteDenied)
+	}
+	if string(buffer[2:2+reasonSize]) != reason {
+		t.Errorf("expected: %s, received: %s", reason, string(buffer[2:2+reasonSize]))
+	}
+}
+
+func TestCreateNamedHelloMessage(t *testing.T) {
+	port := uint16(4444)
+	name := "ian"
+	buffer, err := CreateNamedHelloMessage(port, name)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	commandType := CommandType(buffer[0])
+	udp := binary.BigEndian.Uint16(buffer[1:3])
+	nameSize := int(buffer[3])
+	if commandType != Hello {
+		t.Errorf("expected: %d == %d, received: false", commandType, Hello)
+	}
+	if udp != port {
+		t.Errorf("expected: %d == %d, received: false", udp, port)
+	}
+	if string(buffer[4:4+nameSize]) != name {
+		t.Errorf("expected: %s, received: %s", name, string(buffer[4:4+nameSize]))
+	}
+}
+
+func TestCreateChatMessage(t *testing.T) {
+	text := "this song slaps"
+	buffer, err := CreateChatMessage(text)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	commandType := CommandType(buffer[0])
+	textSize := int(buffer[1])
+	if commandType != ChatMessage {
+		t.Errorf("expected: %d == %d, received: false", commandType, ChatMessage)
+	}
+	if string(buffer[2:2+textSize]) != text {
+		t.Errorf("expected: %s, received: %s", text, string(buffer[2:2+textSize]))
+	}
+}
+
+func TestCreateChatReplyMessage(t *testing.T) {
+	currStation := uint16(2)
+	name := "ian"
+	text := "this song slaps"
+	buffer, err := CreateChatReplyMessage(currStation, name, text)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	station := binary.BigEndian.Uint16(buffer[1:3])
+	nameSize := int(buffer[3])
+	textSize := int(buffer[4])
+	if replyType != ChatReply {
+		t.Errorf("expected: %d == %d, received: false", replyType, ChatReply)
+	}
+	if station != currStation {
+		t.Errorf("expected: %d == %d, received: false", station, currStation)
+	}
+	if string(buffer[5:5+nameSize]) != name {
+		t.Errorf("expected: %s, received: %s", name, string(buffer[5:5+nameSize]))
+	}
+	if string(buffer[5+nameSize:5+nameSize+textSize]) != text {
+		t.Errorf("expected: %s, received: %s", text, string(buffer[5+nameSize:5+nameSize+textSize]))
+	}
+}
+
+func TestCreateChatDeniedMessage(t *testing.T) {
+	reason := "You are muted"
+	buffer, err := CreateChatDeniedMessage(reason)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	reasonSize := int(buffer[1])
+	if replyType != ChatDenied {
+		t.Errorf("expected: %d == %d, received: false", replyType, ChatDenied)
+	}
+	if string(buffer[2:2+reasonSize]) != reason {
+		t.Errorf("expected: %s, received: %s", reason, string(buffer[2:2+reasonSize]))
+	}
+}
+
+func TestCreateWatchMessage(t *testing.T) {
+	stationNums := []uint16{0, 3, 300}
+	for _, expected := range []CommandType{Watch, Unwatch} {
+		create := CreateWatchMessage
+		if expected == Unwatch {
+			create = CreateUnwatchMessage
+		}
+		buffer, err := create(stationNums)
+		if err != nil {
+			t.Errorf("expected: nil, received: %v", err)
+		}
+		commandType := CommandType(buffer[0])
+		if commandType != expected {
+			t.Errorf("expected: %d == %d, received: false", commandType, expected)
+		}
+		parsed, err := ParseWatchMessage(buffer)
+		if err != nil {
+			t.Fatalf("expected: nil, received: %v", err)
+		}
+		if fmt.Sprint(parsed) != fmt.Sprint(stationNums) {
+			t.Errorf("expected: %v, received: %v", stationNums, parsed)
+		}
+		_, err = ParseWatchMessage(buffer[:len(buffer)-1])
+		if err == nil {
+			t.Errorf("expected: error, received: nil")
+		}
+	}
+	_, err := CreateWatchMessage(make([]uint16, 256))
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+}
+
+func TestCreateWatchedSongMessage(t *testing.T) {
+	currStation := uint16(3)
+	songName := "IceIceBaby.mp3"
+	buffer, err := CreateWatchedSongMessage(currStation, songName)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	station := binary.BigEndian.Uint16(buffer[1:3])
+	songNameSize := int(buffer[3])
+	if replyType != WatchedSong {
+		t.Errorf("expected: %d == %d, received: false", replyType, WatchedSong)
+	}
+	if station != currStation {
+		t.Errorf("expected: %d == %d, received: false", station, currStation)
+	}
+	if string(buffer[4:4+songNameSize]) != songName {
+		t.Errorf("expected: %s, received: %s", songName, string(buffer[4:4+songNameSize]))
+	}
+}
+
+func TestCreateWatchingMessage(t *testing.T) {
+	stations := []WatchedStation{
+		{Station: 0, Song: "mp3/tinyfile"},
+		{Station: 9, Song: "Live: morning show"},
+	}
+	buffer, err := CreateWatchingMessage(stations)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	if replyType != Watching {
+		t.Errorf("expected: %d == %d, received: false", replyType, Watching)
+	}
+	if WatchingLength(buffer) != len(buffer) {
+		t.Errorf("expected: %d, received: %d", len(buffer), WatchingLength(buffer))
+	}
+	parsed, err := ParseWatchingMessage(buffer)
+	if err != nil {
+		t.Fatalf("expected: nil, received: %v", err)
+	}
+	if len(parsed) != len(stations) {
+		t.Fatalf("expected: %d, received: %d", len(stations), len(parsed))
+	}
+	for idx, station := range stations {
+		if parsed[idx] != station {
+			t.Errorf("expected: %v, received: %v", station, parsed[idx])
+		}
+	}
+	_, err = ParseWatchingMessage(buffer[:len(buffer)-1])
+	if err == nil {
+		t.Errorf("expected: error, received: nil")
+	}
+}
+
+func TestCreateWatchDeniedMessage(t *testing.T) {
+	reason := "You are not watching station 3"
+	buffer, err := CreateWatchDeniedMessage(reason)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	reasonSize := int(buffer[1])
+	if replyType != WatchDenied {
+		t.Errorf("expected: %d == %d, received: false", replyType, WatchDenied)
+	}
+	if string(buffer[2:2+reasonSize]) != reason {
+		t.Errorf("expected: %s, received: %s", reason, string(buffer[2:2+reasonSize]))
+	}
+}
+
+func TestCreateStopListeningMessage(t *testing.T) {
+	buffer, err := CreateStopListeningMessage()
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	commandType := CommandType(buffer[0])
+	if commandType != StopListening {
+		t.Errorf("expected: %d == %d, received: false", commandType, StopListening)
+	}
+}
+
+func TestCreateStoppedListeningMessage(t *testing.T) {
+	currStation := uint16(6)
+	buffer, err := CreateStoppedListeningMessage(currStation)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	station := binary.BigEndian.Uint16(buffer[1:3])
+	if replyType != StoppedListening {
+		t.Errorf("expected: %d == %d, received: false", replyType, StoppedListening)
+	}
+	if station != currStation {
+		t.Errorf("expected: %d == %d, received: false", station, currStation)
+	}
+}
+
+func TestCreateStopDeniedMessage(t *testing.T) {
+	reason := "You are not listening to a station"
+	buffer, err := CreateStopDeniedMessage(reason)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	reasonSize := int(buffer[1])
+	if replyType != StopDenied {
+		t.Errorf("expected: %d == %d, received: false", replyType, StopDenied)
+	}
+	if string(buffer[2:2+reasonSize]) != reason {
+		t.Errorf("expected: %s, received: %s", reason, string(buffer[2:2+reasonSize]))
+	}
+}
+
+func TestCreatePingMessage(t *testing.T) {
+	buffer, err := CreatePingMessage()
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	commandType := CommandType(buffer[0])
+	if commandType != Ping {
+		t.Errorf("expected: %d == %d, received: false", commandType, Ping)
+	}
+}
+
+func TestCreatePongMessage(t *testing.T) {
+	interval := uint32(5000)
+	buffer, err := CreatePongMessage(interval)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	received := binary.BigEndian.Uint32(buffer[1:5])
+	if replyType != Pong {
+		t.Errorf("expected: %d == %d, received: false", replyType, Pong)
+	}
+	if received != interval {
+		t.Errorf("expected: %d, received: %d", interval, received)
+	}
+}
+
+func TestCreateServerShutdownMessage(t *testing.T) {
+	reason := "Restarting for an update"
+	seconds := uint16(30)
+	buffer, err := CreateServerShutdownMessage(reason, seconds)
+	if err != nil {
+		t.Errorf("expected: nil, received: %v", err)
+	}
+	replyType := ReplyType(buffer[0])
+	reconnectAfter := binary.BigEndian.Uint16(buffer[1:3])
+	reasonSize := int(buffer[3])
+	if replyType != ServerShutdown {
+		t.Errorf("expected: %d == %d, received: false", replyType, ServerShutdown)
+	}
+	if reconnectAfter != seconds {
+		t.Errorf("expected: %d == %d, received: false", reconnectAfter, seconds)
+	}
+	if string(buffer[4:4+reasonSize]) != reason {
+		t.Errorf("expected: %s, received: %s", reason, string(buffer[4:4+reasonSize]))
+	}
+}